@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
 	"github.com/ake3mio/go-todo-cli/internal/tui/add"
@@ -8,21 +13,72 @@ import (
 )
 
 var addCmd = &cobra.Command{
-	Use:        string(tui.AddTask),
+	Use:        string(tui.AddTask) + " [title]",
 	Aliases:    nil,
 	SuggestFor: nil,
 	Short:      "Add a task to do",
 	Long: `
 Type the task name and due date, then press Enter to save it.
 Once the task is added, you’ll automatically return to the task list view.
+
+Pass the title as an argument to add the task without opening the form.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			t, err := loadTheme()
+			if err != nil {
+				return err
+			}
+			keys, err := loadKeyMap()
+			if err != nil {
+				return err
+			}
+			clearScreen()
+			runner := add.NewAdd(persistence.NewTodoRepository(), t, keys)
+			return runner.Run(rootCmd)
+		}
+
 		repository := persistence.NewTodoRepository()
-		runner := add.NewAdd(repository)
-		return runner.Run(rootCmd)
+		defer repository.Close()
+		task, err := taskFromFlags(cmd, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		id, err := repository.CreateTask(task)
+		if err != nil {
+			return err
+		}
+		task.Id = id
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), formatTask(task))
+		return nil
 	},
 }
 
+func taskFromFlags(cmd *cobra.Command, title string) (data.Task, error) {
+	task := data.Task{Title: title}
+
+	dueFlag, _ := cmd.Flags().GetString("due")
+	due, err := time.Parse(time.DateOnly, dueFlag)
+	if err != nil {
+		return task, err
+	}
+	task.DueDate = due
+
+	priorityFlag, _ := cmd.Flags().GetString("priority")
+	if task.Priority, err = data.ParsePriority(priorityFlag); err != nil {
+		return task, err
+	}
+	task.Project, _ = cmd.Flags().GetString("project")
+	task.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	task.Notes, _ = cmd.Flags().GetString("notes")
+	return task, nil
+}
+
 func init() {
+	addCmd.Flags().String("due", time.Now().Format(time.DateOnly), "Due date (YYYY-MM-DD)")
+	addCmd.Flags().StringP("priority", "p", "", "Priority from A (highest) to Z")
+	addCmd.Flags().String("project", "", "Project the task belongs to")
+	addCmd.Flags().StringSlice("tag", nil, "Tag to attach (repeatable)")
+	addCmd.Flags().String("notes", "", "Free-form notes shown in the task details")
 	rootCmd.AddCommand(addCmd)
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/agenda"
+	"github.com/ake3mio/go-todo-cli/internal/config"
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Print a summary of overdue, due and recently completed tasks",
+	Long: `
+Print what is overdue, due today, due later this week and completed since yesterday. With --week the
+week covers the next seven days and completed tasks the last seven.
+
+Sections can be picked and ordered with --sections or "agenda": {"sections": [...]} in the config
+file. Add "todo agenda" to your shell's login file for a morning summary.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		weekly, _ := cmd.Flags().GetBool("week")
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatText && format != formatJSON {
+			return fmt.Errorf("unknown agenda format %q: use text or json", format)
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		names := cfg.Agenda.Sections
+		if cmd.Flags().Changed("sections") {
+			names, _ = cmd.Flags().GetStringSlice("sections")
+		}
+		sections, err := agenda.ParseSections(names)
+		if err != nil {
+			return err
+		}
+		options := agenda.Options{Sections: sections, Weekly: weekly}
+		now := time.Now()
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		open, err := repository.FindTasks(persistence.ListOptions{Sort: data.SortByDue, HideCompleted: true})
+		if err != nil {
+			return err
+		}
+		completed, err := repository.GetCompletedTasks(options.CompletedSince(now))
+		if err != nil {
+			return err
+		}
+
+		a := agenda.Build(open, completed, now, options)
+		if format == formatJSON {
+			return agenda.WriteJSON(cmd.OutOrStdout(), a)
+		}
+		t, err := loadTheme()
+		if err != nil {
+			return err
+		}
+		return agenda.Write(cmd.OutOrStdout(), a, t)
+	},
+}
+
+func init() {
+	agendaCmd.Flags().Bool("week", false, "Cover the next seven days instead of the rest of this week")
+	agendaCmd.Flags().StringP("format", "f", formatText, "Output format: text or json")
+	agendaCmd.Flags().StringSlice("sections", nil, "Sections to show, in order: overdue, today, week, completed")
+	rootCmd.AddCommand(agendaCmd)
+}
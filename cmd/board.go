@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/board"
+	"github.com/spf13/cobra"
+)
+
+var boardCmd = &cobra.Command{
+	Use:   string(tui.Board),
+	Short: "Show tasks on a board with a column per status",
+	Long: `
+Show a column for each status listed by todo statuses, with the tasks in it. Move between columns
+with the arrow keys and move the selected task to the previous or next status with shift+left and
+shift+right (or H and L). Moving a task to a terminal status completes it.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTheme()
+		if err != nil {
+			return err
+		}
+		keys, err := loadKeyMap()
+		if err != nil {
+			return err
+		}
+		clearScreen()
+		runner := board.NewBoard(persistence.NewTodoRepository(), board.Options{Theme: t, Keys: keys})
+		return runner.Run(rootCmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(boardCmd)
+}
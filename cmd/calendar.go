@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/calendar"
+	"github.com/spf13/cobra"
+)
+
+var calendarCmd = &cobra.Command{
+	Use:   string(tui.Calendar),
+	Short: "Show tasks on a month calendar",
+	Long: `
+Show a month grid with the number of open tasks due each day. Move between days with the arrow
+keys and between months with pgup/pgdown, and press enter to list a day's tasks, where they can be
+completed, renamed or moved to another date.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := loadTheme()
+		if err != nil {
+			return err
+		}
+		keys, err := loadKeyMap()
+		if err != nil {
+			return err
+		}
+		clearScreen()
+		runner := calendar.NewCalendar(persistence.NewTodoRepository(), calendar.Options{Theme: t, Keys: keys})
+		return runner.Run(rootCmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(calendarCmd)
+}
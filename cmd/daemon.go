@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+// daemonStartTimeout bounds how long `daemon start` waits for the socket.
+const daemonStartTimeout = 10 * time.Second
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a background process that owns the task database",
+	Long: `
+Run a daemon that owns the task database and serves it to other todo
+processes as JSON-RPC over a Unix socket beside the database (or at
+TODO_SOCKET). While it runs, every todo command and view goes through it, so
+concurrent clients queue instead of contending for SQLite locks. When it is
+not running they open the database directly.
+`,
+}
+
+var daemonRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the daemon in the foreground",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket := persistence.SocketPath()
+		if _, err := persistence.GetDaemonStatus(); err == nil {
+			return fmt.Errorf("a daemon is already listening on %s", socket)
+		}
+		// Anything left at the path belongs to a daemon that is gone.
+		if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		repository := persistence.NewLocalRepository()
+		defer repository.Close()
+
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(socket)
+		if err := os.Chmod(socket, 0o600); err != nil {
+			_ = listener.Close()
+			return err
+		}
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			<-signals
+			_ = listener.Close()
+		}()
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s daemon listening on %s\n", time.Now().Format(data.TimestampLayout), socket)
+		stop := func() { _ = listener.Close() }
+		return persistence.Serve(listener, repository, stop)
+	},
+}
+
+var daemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the daemon in the background",
+	Long: `
+Start the daemon as a background process and wait until it is accepting
+connections. Its output is appended to the socket path plus ".log".
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if status, err := persistence.GetDaemonStatus(); err == nil {
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "daemon already running (pid %d)\n", status.Pid)
+			return err
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		logPath := persistence.SocketPath() + ".log"
+		log, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+
+		daemon := exec.Command(executable, "daemon", "run")
+		daemon.Stdout = log
+		daemon.Stderr = log
+		daemon.SysProcAttr = detached()
+		if err := daemon.Start(); err != nil {
+			return err
+		}
+		exited := make(chan error, 1)
+		go func() { exited <- daemon.Wait() }()
+
+		deadline := time.After(daemonStartTimeout)
+		for {
+			if status, err := persistence.GetDaemonStatus(); err == nil {
+				_, err := fmt.Fprintf(cmd.OutOrStdout(), "daemon started (pid %d)\n", status.Pid)
+				return err
+			}
+			select {
+			case err := <-exited:
+				return fmt.Errorf("daemon exited during start-up (%v); see %s", err, logPath)
+			case <-deadline:
+				return fmt.Errorf("daemon did not start within %s; see %s", daemonStartTimeout, logPath)
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := persistence.StopDaemon(); err != nil {
+			return fmt.Errorf("no daemon running on %s", persistence.SocketPath())
+		}
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "daemon stopped")
+		return err
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon is running",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w := cmd.OutOrStdout()
+		status, err := persistence.GetDaemonStatus()
+		if err != nil {
+			_, err := fmt.Fprintf(w, "not running (nothing listening on %s)\n", persistence.SocketPath())
+			return err
+		}
+		_, err = fmt.Fprintf(w, "running (pid %d) since %s\ndatabase %s\nsocket   %s\n",
+			status.Pid, status.Since.Local().Format(data.TimestampLayout), status.Database, persistence.SocketPath())
+		return err
+	},
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonRunCmd, daemonStartCmd, daemonStopCmd, daemonStatusCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
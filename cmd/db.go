@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Back up, restore and check the task database",
+	Long: `
+Maintain the SQLite task database.
+
+An automatic backup is also taken before migrations change the schema and
+before a restore, in a .backups directory beside the database. The newest
+five are kept.
+`,
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup <path>",
+	Short: "Write a consistent copy of the database to a new file",
+	Long: `
+Write a consistent, compacted copy of the database to path. It is safe to run
+while the database is in use. path must not exist yet.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := persistence.Backup(args[0]); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(cmd.OutOrStdout(), "backed up to %s\n", args[0])
+		return err
+	},
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <path>",
+	Short: "Replace the database with a backup",
+	Long: `
+Replace the database with the backup at path, then bring it up to the current
+schema. The backup must pass the integrity check and must not come from a newer
+version of todo. The database being replaced is saved as an automatic backup
+first.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		previous, err := persistence.Restore(args[0])
+		if err != nil {
+			return err
+		}
+		w := cmd.OutOrStdout()
+		_, _ = fmt.Fprintf(w, "restored from %s\n", args[0])
+		if previous != "" {
+			_, _ = fmt.Fprintf(w, "previous database saved to %s\n", previous)
+		}
+		return nil
+	},
+}
+
+var dbCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the database for corruption",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		problems, err := persistence.Check()
+		if err != nil {
+			return err
+		}
+		w := cmd.OutOrStdout()
+		if len(problems) == 0 {
+			_, err := fmt.Fprintln(w, "ok")
+			return err
+		}
+		for _, problem := range problems {
+			_, _ = fmt.Fprintln(w, problem)
+		}
+		return fmt.Errorf("integrity check found %d problem(s)", len(problems))
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbBackupCmd, dbRestoreCmd, dbCheckCmd)
+	rootCmd.AddCommand(dbCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:     "delete <id>...",
+	Aliases: []string{"rm"},
+	Short:   "Delete tasks",
+	Long: `
+Delete tasks by id, all or none. Protected tasks are refused unless --force is given.
+History is kept after a task is deleted.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIds(args)
+		if err != nil {
+			return err
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		deleted, err := repository.DeleteTasks(ids, force)
+		var p *persistence.ProtectedError
+		if errors.As(err, &p) {
+			return fmt.Errorf("%w; nothing was deleted. Unprotect it with `todo protect --off %d` or use --force", err, p.Id)
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "deleted %d task(s)\n", deleted)
+		if missing := len(ids) - deleted; missing > 0 {
+			return fmt.Errorf("%d of the tasks given did not exist", missing)
+		}
+		return nil
+	},
+}
+
+var protectCmd = &cobra.Command{
+	Use:   "protect <id>...",
+	Short: "Protect tasks from deletion",
+	Long: `
+Protect tasks so that deleting them, from the list or with todo delete, is refused unless forced.
+Use --off to remove the protection.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIds(args)
+		if err != nil {
+			return err
+		}
+		off, _ := cmd.Flags().GetBool("off")
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		tasks := make([]data.Task, len(ids))
+		for i, id := range ids {
+			if tasks[i], err = repository.GetTask(id); err != nil {
+				return err
+			}
+			tasks[i].Protected = !off
+		}
+		if err := repository.UpdateTasks(tasks); err != nil {
+			return err
+		}
+		state := "protected"
+		if off {
+			state = "unprotected"
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %d task(s)\n", state, len(ids))
+		return nil
+	},
+}
+
+func parseIds(args []string) ([]int, error) {
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task id %q", arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func init() {
+	deleteCmd.Flags().Bool("force", false, "Delete protected tasks too")
+	protectCmd.Flags().Bool("off", false, "Remove the protection instead")
+	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(protectCmd)
+}
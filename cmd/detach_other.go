@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cmd
+
+import "syscall"
+
+func detached() *syscall.SysProcAttr {
+	return nil
+}
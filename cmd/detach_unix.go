@@ -0,0 +1,10 @@
+//go:build unix
+
+package cmd
+
+import "syscall"
+
+// detached starts a child in its own session so it outlives the terminal.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
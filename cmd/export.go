@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/ical"
+	"github.com/ake3mio/go-todo-cli/internal/markdown"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/taskwarrior"
+	"github.com/ake3mio/go-todo-cli/internal/todotxt"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatTodoTxt     = "todotxt"
+	formatICS         = "ics"
+	formatMarkdown    = "markdown"
+	formatTaskwarrior = "taskwarrior"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tasks to another format",
+	Long: `
+Write every task to standard output, or to --output, in another format.
+
+Formats:
+  todotxt      todo.txt, one task per line
+  ics          iCalendar (RFC 5545) VTODO components
+  markdown     Markdown checklist, with a heading per --group and subtasks nested
+  taskwarrior  Taskwarrior JSON, as read by "task import"
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		group, _ := cmd.Flags().GetString("group")
+		groupBy, err := data.ParseGroupBy(group)
+		if err != nil {
+			return err
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		tasks, err := repository.GetTasks()
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return writeTasks(w, format, tasks, groupBy)
+	},
+}
+
+func writeTasks(w io.Writer, format string, tasks []data.Task, groupBy data.GroupBy) error {
+	switch format {
+	case formatTodoTxt:
+		return todotxt.Write(w, tasks)
+	case formatICS:
+		return ical.Write(w, tasks, time.Now())
+	case formatMarkdown:
+		return markdown.Write(w, tasks, groupBy, time.Now())
+	case formatTaskwarrior:
+		return taskwarrior.Write(w, tasks, time.Local)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", formatTodoTxt, "Output format")
+	exportCmd.Flags().StringP("output", "o", "", "File to write instead of standard output")
+	exportCmd.Flags().String("group", string(data.GroupByDue), "Group markdown headings by none, due, project or tag")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/graph"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the links between tasks as a Graphviz graph",
+	Long: `
+Print the tasks that block or wait on others, with an arrow from each blocker to the task it blocks,
+in Graphviz's DOT language. Completed tasks are greyed out and tasks still waiting are outlined in red.
+
+  todo graph | dot -Tsvg > tasks.svg
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		hideCompleted, _ := cmd.Flags().GetBool("hide-completed")
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		tasks, err := repository.FindTasks(persistence.ListOptions{Sort: data.SortByCreated, HideCompleted: hideCompleted})
+		if err != nil {
+			return err
+		}
+		deps, err := repository.GetDependencies()
+		if err != nil {
+			return err
+		}
+		if !all {
+			tasks = graph.Linked(tasks, deps)
+		}
+		return graph.Write(cmd.OutOrStdout(), tasks, deps)
+	},
+}
+
+func init() {
+	graphCmd.Flags().Bool("all", false, "Include tasks that are not linked to any other")
+	graphCmd.Flags().Bool("hide-completed", false, "Omit completed tasks")
+	rootCmd.AddCommand(graphCmd)
+}
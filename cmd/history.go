@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show the change history of a task",
+	Long: `
+Show every recorded change to a task, oldest first, with the old and new value of each field.
+History is kept after a task is deleted.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid task id %q", args[0])
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		events, err := repository.GetTaskHistory(id)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return fmt.Errorf("no history for task %d", id)
+		}
+
+		w := cmd.OutOrStdout()
+		for _, event := range events {
+			_, _ = fmt.Fprintf(w, "%s  %s\n", event.At.Local().Format(data.TimestampLayout), event.Kind)
+			for _, change := range event.Changes {
+				_, _ = fmt.Fprintf(w, "    %s\n", change)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/ical"
+	"github.com/ake3mio/go-todo-cli/internal/interchange"
+	"github.com/ake3mio/go-todo-cli/internal/markdown"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/taskwarrior"
+	"github.com/ake3mio/go-todo-cli/internal/todotxt"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import tasks from another format",
+	Long: `
+Read tasks from a file, or standard input when the file is "-" or left out,
+and add them.
+Tasks that carry the id or UID of an existing task update it instead.
+Use --dry-run to see what would change without writing anything.
+
+The format is taken from --format, or else from the file extension (.ics,
+.md, .json), falling back to todotxt.
+
+Formats:
+  todotxt      todo.txt, one task per line
+  ics          iCalendar (RFC 5545) VTODO components
+  markdown     Markdown checklist items; indented items become subtasks
+  taskwarrior  Taskwarrior JSON from "task export". Tasks are matched by
+               UUID; deleted tasks, recurring templates and attributes
+               with no equivalent are skipped and reported
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "-"
+		if len(args) == 1 {
+			path = args[0]
+		}
+		format := importFormat(cmd, path)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		r := cmd.InOrStdin()
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		incoming, err := readTasks(r, format, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		existing, err := repository.GetTasks()
+		if err != nil {
+			return err
+		}
+
+		plan := interchange.Plan(existing, incoming)
+		if !dryRun {
+			if err := interchange.Apply(repository, plan); err != nil {
+				return err
+			}
+		}
+		return interchange.Report(cmd.OutOrStdout(), plan, dryRun)
+	},
+}
+
+func importFormat(cmd *cobra.Command, path string) string {
+	format, _ := cmd.Flags().GetString("format")
+	if cmd.Flags().Changed("format") {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ics", ".ical":
+		return formatICS
+	case ".md", ".markdown":
+		return formatMarkdown
+	case ".json":
+		return formatTaskwarrior
+	}
+	return format
+}
+
+// readTasks parses r in format. Anything the format could not carry over is
+// reported to warnings.
+func readTasks(r io.Reader, format string, warnings io.Writer) ([]data.Task, error) {
+	switch format {
+	case formatTodoTxt:
+		return todotxt.Parse(r)
+	case formatICS:
+		return ical.Parse(r)
+	case formatMarkdown:
+		return markdown.Parse(r)
+	case formatTaskwarrior:
+		tasks, report, err := taskwarrior.Parse(r, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		return tasks, report.Write(warnings)
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+}
+
+func init() {
+	importCmd.Flags().StringP("format", "f", formatTodoTxt, "Input format")
+	importCmd.Flags().Bool("dry-run", false, "Report what would change without importing")
+	rootCmd.AddCommand(importCmd)
+}
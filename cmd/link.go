@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var linkCmd = &cobra.Command{
+	Use:   "link <id> --blocks <id>...",
+	Short: "Record that a task blocks others",
+	Long: `
+Record that task id has to be completed before the tasks given with --blocks can be. Completing a
+task with open blockers is refused. The links are made all together or, when one would make a cycle
+or names a missing task, not at all. Links and their removal show up in todo history.
+Use --off to remove the links.
+
+  todo link 3 --blocks 4,5
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIds(args)
+		if err != nil {
+			return err
+		}
+		blocks, _ := cmd.Flags().GetIntSlice("blocks")
+		off, _ := cmd.Flags().GetBool("off")
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		if off {
+			err = repository.UnlinkTasks(ids[0], blocks)
+		} else {
+			err = repository.LinkTasks(ids[0], blocks)
+		}
+		if err != nil {
+			return err
+		}
+		state := "now blocks"
+		if off {
+			state = "no longer blocks"
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "task %d %s %d task(s)\n", ids[0], state, len(blocks))
+		return nil
+	},
+}
+
+func init() {
+	linkCmd.Flags().IntSlice("blocks", nil, "Tasks that wait on this one (comma separated)")
+	linkCmd.Flags().Bool("off", false, "Remove the links instead")
+	_ = linkCmd.MarkFlagRequired("blocks")
+	rootCmd.AddCommand(linkCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "List completed tasks",
+	Long: `
+List tasks completed within a window, oldest completion first.
+The window accepts days (7d), weeks (2w), durations (36h) or a date (YYYY-MM-DD).
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		since, err := data.ParseSince(sinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		tasks, err := repository.GetCompletedTasks(since)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		if len(tasks) == 0 {
+			_, _ = fmt.Fprintf(w, "No tasks completed since %s\n", since.Format(data.TimestampLayout))
+			return nil
+		}
+		for _, task := range tasks {
+			_, _ = fmt.Fprintf(w, "%s  %s\n", task.CompletedAt.Local().Format(data.TimestampLayout), formatTask(task))
+		}
+		return nil
+	},
+}
+
+func init() {
+	logCmd.Flags().String("since", "7d", "How far back to look")
+	rootCmd.AddCommand(logCmd)
+}
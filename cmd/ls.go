@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Print tasks",
+	Long: `
+Print tasks to standard output, honouring the same sort and group flags as the list view.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := listOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		hideCompleted, _ := cmd.Flags().GetBool("hide-completed")
+		long, _ := cmd.Flags().GetBool("long")
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		tasks, err := repository.FindTasks(persistence.ListOptions{Sort: options.Sort, Desc: options.Desc, HideCompleted: hideCompleted})
+		if err != nil {
+			return err
+		}
+
+		printGroups(cmd.OutOrStdout(), data.GroupTasks(tasks, options.GroupBy, time.Now()), options.GroupBy != data.GroupByNone, long)
+		return nil
+	},
+}
+
+func printGroups(w io.Writer, groups []data.Group, headers bool, long bool) {
+	for i, group := range groups {
+		if headers {
+			if i > 0 {
+				_, _ = fmt.Fprintln(w)
+			}
+			_, _ = fmt.Fprintf(w, "%s\n", group.Name)
+		}
+		for _, task := range group.Tasks {
+			_, _ = fmt.Fprintln(w, formatTask(task))
+			if long {
+				_, _ = fmt.Fprintf(w, "    %s\n", formatTimestamps(task))
+			}
+		}
+	}
+}
+
+// formatTask is data.FormatTask followed by the due date.
+func formatTask(task data.Task) string {
+	line := data.FormatTask(task)
+	if !task.DueDate.IsZero() {
+		line += " ~ due " + task.DueDate.Format(time.DateOnly)
+	}
+	return line
+}
+
+func formatTimestamps(task data.Task) string {
+	stamp := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format(data.TimestampLayout)
+	}
+	line := fmt.Sprintf("%s, created %s, updated %s", task.Status, stamp(task.CreatedAt), stamp(task.UpdatedAt))
+	if task.Complete {
+		line += ", completed " + stamp(task.CompletedAt)
+	}
+	return line
+}
+
+func init() {
+	addListFlags(lsCmd)
+	lsCmd.Flags().Bool("hide-completed", false, "Omit completed tasks")
+	lsCmd.Flags().BoolP("long", "l", false, "Show created, updated and completed timestamps")
+	rootCmd.AddCommand(lsCmd)
+}
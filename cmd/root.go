@@ -5,8 +5,12 @@ import (
 	"os/exec"
 	"runtime"
 
+	"github.com/ake3mio/go-todo-cli/internal/config"
+	"github.com/ake3mio/go-todo-cli/internal/data"
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
 	"github.com/ake3mio/go-todo-cli/internal/tui/list"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 	"github.com/spf13/cobra"
 )
 
@@ -17,13 +21,79 @@ var rootCmd = &cobra.Command{
 View and manage tasks.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := listOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if options.Theme, err = loadTheme(); err != nil {
+			return err
+		}
+		if options.Keys, err = loadKeyMap(); err != nil {
+			return err
+		}
+		if options.ConfirmDelete, err = loadConfirmDelete(); err != nil {
+			return err
+		}
+		clearScreen()
 		repository := persistence.NewTodoRepository()
-		runner := list.NewList(repository)
+		runner := list.NewList(repository, options)
 		return runner.Run(cmd)
 	},
 }
 
-func Execute() {
+// loadTheme resolves the theme named in the user's config file.
+func loadTheme() (theme.Theme, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return theme.Theme{}, err
+	}
+	return theme.Resolve(cfg.Theme, cfg.Themes)
+}
+
+// loadKeyMap builds the key map configured in the user's config file,
+// refusing one that binds a key to two actions.
+func loadKeyMap() (keymap.KeyMap, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return keymap.KeyMap{}, err
+	}
+	return keymap.Resolve(cfg.Keys.Preset, cfg.Keys.Bindings)
+}
+
+// loadConfirmDelete reads which deletes the list view asks to confirm.
+func loadConfirmDelete() (list.ConfirmDelete, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return list.ParseConfirmDelete(cfg.List.ConfirmDelete)
+}
+
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("sort", string(data.SortByDue), "Sort tasks by due, created, priority, title or completion")
+	cmd.Flags().Bool("desc", false, "Sort in descending order")
+	cmd.Flags().String("group", string(data.GroupByNone), "Group tasks by none, due, project or tag")
+}
+
+func listOptionsFromFlags(cmd *cobra.Command) (list.Options, error) {
+	var options list.Options
+	sortFlag, _ := cmd.Flags().GetString("sort")
+	sortKey, err := data.ParseSortKey(sortFlag)
+	if err != nil {
+		return options, err
+	}
+	groupFlag, _ := cmd.Flags().GetString("group")
+	groupBy, err := data.ParseGroupBy(groupFlag)
+	if err != nil {
+		return options, err
+	}
+	desc, _ := cmd.Flags().GetBool("desc")
+	return list.Options{Sort: sortKey, Desc: desc, GroupBy: groupBy}, nil
+}
+
+// clearScreen wipes the terminal before an interactive view starts. Commands
+// that print to stdout skip it so their output can be piped.
+func clearScreen() {
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
 		cmd = exec.Command("cmd", "/c", "cls")
@@ -33,6 +103,9 @@ func Execute() {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	_ = cmd.Run()
+}
+
+func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -41,4 +114,5 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	addListFlags(rootCmd)
 }
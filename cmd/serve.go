@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/api"
+	"github.com/ake3mio/go-todo-cli/internal/config"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve tasks over a local HTTP JSON API",
+	Long: `
+Serve a REST API over the task database until interrupted, for editor plugins
+and other local tools.
+
+  GET    /tasks                 list tasks (?status=open|done, project, tag, q,
+                                due_before=YYYY-MM-DD, sort, desc=true)
+  POST   /tasks                 create a task
+  GET    /tasks/{id}            get a task
+  PATCH  /tasks/{id}            change some fields of a task
+  DELETE /tasks/{id}            delete a task (?force=true if it is protected)
+  POST   /tasks/{id}/complete   mark a task complete
+  GET    /schemas/{name}        JSON Schemas: task.json, task-list.json,
+                                task-create.json, task-patch.json
+
+Responses carry an ETag; send it back in If-Match to have a change refused with
+412 if the task was modified in the meantime. Completing a task that waits on
+open tasks (see todo link) is refused with 409.
+
+When "server": {"token": "..."} is set in the config file, clients must send
+"Authorization: Bearer <token>". Without a token the server only listens on a
+loopback address and only answers requests addressed to a loopback host.
+
+Request bodies must be sent with Content-Type: application/json, and requests
+from another site's Origin are refused.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Server.Token == "" && !isLoopback(addr) {
+			return fmt.Errorf("refusing to serve on %s without a token; set server.token in %s", addr, config.Path())
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+		server := &http.Server{
+			Handler:           api.NewHandler(repository, api.Options{Token: cfg.Server.Token}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdown)
+		}()
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "serving tasks on http://%s\n", listener.Addr())
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// isLoopback reports whether addr only accepts connections from this machine.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:7777", "Address to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze <id>... <when>",
+	Short: "Push tasks out to a later due date",
+	Long: `
+Move the due date of tasks to when: today, tomorrow, next-week (the coming Monday), next-month (the
+first of next month), a number of days or weeks from today (3d, 2w), a duration from now (36h) or a
+date (2025-10-01). The change is recorded in each task's history.
+
+With --overdue, every open task due before today is moved to when, today by default.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if overdue, _ := cmd.Flags().GetBool("overdue"); overdue {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		overdue, _ := cmd.Flags().GetBool("overdue")
+		when := data.SnoozeToday
+		if len(args) > 0 {
+			when = args[len(args)-1]
+		}
+		now := time.Now()
+		due, err := data.ParseSnooze(when, now)
+		if err != nil {
+			return err
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		var tasks []data.Task
+		if overdue {
+			open, err := repository.FindTasks(persistence.ListOptions{HideCompleted: true})
+			if err != nil {
+				return err
+			}
+			for _, task := range open {
+				if data.IsOverdue(task, now) {
+					tasks = append(tasks, task)
+				}
+			}
+		} else {
+			ids, err := parseIds(args[:len(args)-1])
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				task, err := repository.GetTask(id)
+				if err != nil {
+					return err
+				}
+				tasks = append(tasks, task)
+			}
+		}
+		for i := range tasks {
+			tasks[i].DueDate = due
+		}
+		if err := repository.UpdateTasks(tasks); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "snoozed %d task(s) to %s\n", len(tasks), due.Format(time.DateOnly))
+		return nil
+	},
+}
+
+func init() {
+	snoozeCmd.Flags().Bool("overdue", false, "Snooze every overdue task")
+	rootCmd.AddCommand(snoozeCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status <id>... <status>",
+	Short: "Move tasks to a status",
+	Long: `
+Move tasks to status, one of those listed by todo statuses. Moving a task to a terminal status
+completes it; moving it to any other status reopens it. The change is recorded in each task's history.
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIds(args[:len(args)-1])
+		if err != nil {
+			return err
+		}
+		status := args[len(args)-1]
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		tasks := make([]data.Task, len(ids))
+		for i, id := range ids {
+			if tasks[i], err = repository.GetTask(id); err != nil {
+				return err
+			}
+			tasks[i].Status = status
+		}
+		err = repository.UpdateTasks(tasks)
+		var b *persistence.BlockedError
+		if errors.As(err, &b) {
+			return fmt.Errorf("%w; nothing was moved. Complete them first or remove the links with `todo link --off`", err)
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "moved %d task(s) to %s\n", len(ids), status)
+		return nil
+	},
+}
+
+var statusesCmd = &cobra.Command{
+	Use:   "statuses",
+	Short: "List the statuses tasks move through",
+	Long: `
+List the workflow's statuses in board order. Tasks in a terminal status are complete.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		statuses, err := repository.GetStatuses()
+		if err != nil {
+			return err
+		}
+		w := cmd.OutOrStdout()
+		for _, status := range statuses {
+			line := status.Name
+			if status.Terminal {
+				line += " (terminal)"
+			}
+			_, _ = fmt.Fprintln(w, line)
+		}
+		return nil
+	},
+}
+
+var statusesSetCmd = &cobra.Command{
+	Use:   "set <status>...",
+	Short: "Replace the statuses tasks move through",
+	Long: `
+Replace the workflow with the statuses given, in board order. Name the terminal ones with --terminal;
+at least one status must be terminal and one must not. Statuses that tasks are in cannot be removed.
+Tasks in a status that becomes terminal are completed, and those in one that stops being terminal
+are reopened.
+
+  todo statuses set todo in-progress review done wontfix --terminal done,wontfix
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		terminal, _ := cmd.Flags().GetStringSlice("terminal")
+		for _, name := range terminal {
+			if !slices.Contains(args, name) {
+				return fmt.Errorf("terminal status %q is not one of the statuses given", name)
+			}
+		}
+		statuses := make([]data.Status, len(args))
+		for i, name := range args {
+			statuses[i] = data.Status{Name: name, Terminal: slices.Contains(terminal, name)}
+		}
+
+		repository := persistence.NewTodoRepository()
+		defer repository.Close()
+		err := repository.SetStatuses(statuses)
+		var b *persistence.BlockedError
+		if errors.As(err, &b) {
+			return fmt.Errorf("%w; the statuses were not changed. Complete them or move task %d to another status first", err, b.Id)
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "set %d status(es)\n", len(statuses))
+		return nil
+	},
+}
+
+func init() {
+	statusesSetCmd.Flags().StringSlice("terminal", nil, "Statuses that complete a task (comma separated)")
+	statusesCmd.AddCommand(statusesSetCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(statusesCmd)
+}
@@ -0,0 +1,242 @@
+// Package agenda builds the summary printed by `todo agenda`: what is
+// overdue, due today, due in the coming days and recently completed.
+//
+// Everything is computed against a clock passed in by the caller, so the
+// same tasks and the same now always give the same report.
+package agenda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+)
+
+// Section names one part of the agenda. Sections are the values of the
+// "sections" config key and the --sections flag.
+type Section string
+
+const (
+	Overdue   Section = "overdue"
+	Today     Section = "today"
+	Week      Section = "week"
+	Completed Section = "completed"
+)
+
+// DefaultSections is every section, in the order they are shown when none
+// are configured.
+var DefaultSections = []Section{Overdue, Today, Week, Completed}
+
+// ParseSections checks names against the known sections, keeping their
+// order. No names means DefaultSections.
+func ParseSections(names []string) ([]Section, error) {
+	if len(names) == 0 {
+		return DefaultSections, nil
+	}
+	sections := make([]Section, 0, len(names))
+	for _, name := range names {
+		s := Section(strings.TrimSpace(name))
+		switch s {
+		case Overdue, Today, Week, Completed:
+			sections = append(sections, s)
+		default:
+			return nil, fmt.Errorf("unknown agenda section %q: use overdue, today, week or completed", name)
+		}
+	}
+	return sections, nil
+}
+
+// Options configures an agenda.
+type Options struct {
+	// Sections lists the sections to show, in order; nil shows all of them.
+	Sections []Section
+	// Weekly makes the week section cover the next seven days rather than
+	// the rest of the calendar week, and the completed section the last
+	// seven days rather than since yesterday.
+	Weekly bool
+}
+
+// CompletedSince returns when the completed section starts: the start of
+// yesterday, or of the same day a week ago for a weekly agenda.
+func (o Options) CompletedSince(now time.Time) time.Time {
+	days := 1
+	if o.Weekly {
+		days = 7
+	}
+	return data.StartOfDay(now).AddDate(0, 0, -days)
+}
+
+// Group is one section of an agenda and the tasks in it.
+type Group struct {
+	Section Section
+	Title   string
+	Tasks   []data.Task
+}
+
+// Agenda is the report for one day.
+type Agenda struct {
+	Now    time.Time
+	Groups []Group
+}
+
+// Build sorts open tasks into the overdue, today and week sections and lists
+// completed under the completed section, keeping the order they are given
+// in. Sections left out of options are skipped, and so are the tasks that
+// would have gone in them.
+func Build(open []data.Task, completed []data.Task, now time.Time, options Options) Agenda {
+	sections := options.Sections
+	if sections == nil {
+		sections = DefaultSections
+	}
+	today := data.StartOfDay(now)
+	weekEnd := today.AddDate(0, 0, 8)
+	weekTitle := "Next 7 days"
+	completedTitle := "Completed in the last 7 days"
+	if !options.Weekly {
+		weekEnd = data.NextMonday(today)
+		weekTitle = "Later this week"
+		completedTitle = "Completed since yesterday"
+	}
+
+	bySection := map[Section][]data.Task{}
+	for _, task := range open {
+		if task.Complete || task.DueDate.IsZero() {
+			continue
+		}
+		day := data.CalendarDay(task.DueDate, now.Location())
+		switch {
+		case day.Before(today):
+			bySection[Overdue] = append(bySection[Overdue], task)
+		case day.Equal(today):
+			bySection[Today] = append(bySection[Today], task)
+		case day.Before(weekEnd):
+			bySection[Week] = append(bySection[Week], task)
+		}
+	}
+	bySection[Completed] = completed
+
+	titles := map[Section]string{
+		Overdue:   "Overdue",
+		Today:     "Today",
+		Week:      weekTitle,
+		Completed: completedTitle,
+	}
+	a := Agenda{Now: now}
+	for _, s := range sections {
+		a.Groups = append(a.Groups, Group{Section: s, Title: titles[s], Tasks: bySection[s]})
+	}
+	return a
+}
+
+// Empty reports whether no section has any tasks.
+func (a Agenda) Empty() bool {
+	for _, g := range a.Groups {
+		if len(g.Tasks) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// roles colour each section's heading.
+var roles = map[Section]theme.Role{
+	Overdue:   theme.Overdue,
+	Today:     theme.Today,
+	Week:      theme.Soon,
+	Completed: theme.Done,
+}
+
+// Write prints the agenda as text coloured with t, leaving out empty
+// sections. lipgloss leaves the colours out when standard output is not a
+// terminal.
+func Write(w io.Writer, a Agenda, t theme.Theme) error {
+	var b strings.Builder
+	b.WriteString(t.Style(theme.Header).Render("Agenda for "+a.Now.Format("Monday 2 January 2006")) + "\n")
+	if a.Empty() {
+		b.WriteString("\n" + t.Style(theme.Empty).Render("Nothing overdue, due or recently done.") + "\n")
+	}
+	for _, g := range a.Groups {
+		if len(g.Tasks) == 0 {
+			continue
+		}
+		b.WriteString("\n" + t.Style(roles[g.Section]).Render(fmt.Sprintf("%s (%d)", g.Title, len(g.Tasks))) + "\n")
+		for _, task := range g.Tasks {
+			b.WriteString("  " + line(task, a.Now, t) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// line is data.FormatTask followed by when the task was done or, coloured
+// by urgency, when it is due.
+func line(task data.Task, now time.Time, t theme.Theme) string {
+	s := data.FormatTask(task)
+	switch {
+	case task.Complete && !task.CompletedAt.IsZero():
+		s += t.Style(theme.Done).Render(" ~ done " + task.CompletedAt.In(now.Location()).Format(data.TimestampLayout))
+	case !task.Complete && !task.DueDate.IsZero():
+		role := theme.UrgencyRole(data.TaskUrgency(task, now))
+		s += t.Style(role).Render(fmt.Sprintf(" ~ %s (%s)", data.RelativeDue(task.DueDate, now), task.DueDate.Format(time.DateOnly)))
+	}
+	return s
+}
+
+type agendaJSON struct {
+	Date     string        `json:"date"`
+	Sections []sectionJSON `json:"sections"`
+}
+
+type sectionJSON struct {
+	Name  Section    `json:"name"`
+	Title string     `json:"title"`
+	Tasks []taskJSON `json:"tasks"`
+}
+
+type taskJSON struct {
+	Id          int      `json:"id"`
+	Title       string   `json:"title"`
+	Complete    bool     `json:"complete"`
+	Due         string   `json:"due,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Project     string   `json:"project,omitempty"`
+	Tags        []string `json:"tags"`
+	CompletedAt string   `json:"completed_at,omitempty"`
+}
+
+// WriteJSON prints the agenda as JSON, empty sections included, for status
+// bars and scripts.
+func WriteJSON(w io.Writer, a Agenda) error {
+	out := agendaJSON{Date: a.Now.Format(time.DateOnly), Sections: []sectionJSON{}}
+	for _, g := range a.Groups {
+		s := sectionJSON{Name: g.Section, Title: g.Title, Tasks: []taskJSON{}}
+		for _, task := range g.Tasks {
+			j := taskJSON{
+				Id:       task.Id,
+				Title:    task.Title,
+				Complete: task.Complete,
+				Priority: task.Priority,
+				Project:  task.Project,
+				Tags:     task.Tags,
+			}
+			if j.Tags == nil {
+				j.Tags = []string{}
+			}
+			if !task.DueDate.IsZero() {
+				j.Due = task.DueDate.Format(time.DateOnly)
+			}
+			if !task.CompletedAt.IsZero() {
+				j.CompletedAt = task.CompletedAt.UTC().Format(time.RFC3339)
+			}
+			s.Tasks = append(s.Tasks, j)
+		}
+		out.Sections = append(out.Sections, s)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,107 @@
+package agenda
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// Wednesday 1 October 2025, early morning.
+var now = time.Date(2025, time.October, 1, 7, 30, 0, 0, time.UTC)
+
+func day(offset int) time.Time {
+	return time.Date(2025, time.October, 1+offset, 0, 0, 0, 0, time.UTC)
+}
+
+func fixtureOpen() []data.Task {
+	return []data.Task{
+		{Id: 1, Title: "Pay rent", DueDate: day(-2), Priority: "A", Project: "home"},
+		{Id: 2, Title: "Write report", DueDate: day(0), Tags: []string{"work"}},
+		{Id: 3, Title: "Book dentist", DueDate: day(3)},
+		{Id: 4, Title: "Renew passport", DueDate: day(6)},
+		{Id: 5, Title: "Plan holiday"},
+	}
+}
+
+func fixtureCompleted() []data.Task {
+	return []data.Task{
+		{Id: 6, Title: "Draft outline", Complete: true, CompletedAt: time.Date(2025, time.September, 30, 16, 5, 0, 0, time.UTC)},
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWrite_DailyGolden(t *testing.T) {
+	var buf bytes.Buffer
+	a := Build(fixtureOpen(), fixtureCompleted(), now, Options{})
+	require.NoError(t, Write(&buf, a, theme.Default()))
+	golden(t, "daily.txt", buf.Bytes())
+}
+
+func TestWrite_WeeklyGolden(t *testing.T) {
+	var buf bytes.Buffer
+	a := Build(fixtureOpen(), fixtureCompleted(), now, Options{Weekly: true})
+	require.NoError(t, Write(&buf, a, theme.Default()))
+	golden(t, "weekly.txt", buf.Bytes())
+}
+
+func TestWriteJSON_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	a := Build(fixtureOpen(), fixtureCompleted(), now, Options{Sections: []Section{Today, Overdue}})
+	require.NoError(t, WriteJSON(&buf, a))
+	golden(t, "agenda.json", buf.Bytes())
+}
+
+func TestBuild_WeekEndsOnSunday(t *testing.T) {
+	sunday := time.Date(2025, time.October, 5, 9, 0, 0, 0, time.UTC)
+	a := Build(fixtureOpen(), nil, sunday, Options{Sections: []Section{Week}})
+	assert.Empty(t, a.Groups[0].Tasks, "on Sunday the rest of the week is just today")
+
+	a = Build(fixtureOpen(), nil, sunday, Options{Sections: []Section{Week}, Weekly: true})
+	if assert.Len(t, a.Groups[0].Tasks, 1) {
+		assert.Equal(t, 4, a.Groups[0].Tasks[0].Id)
+	}
+}
+
+func TestWrite_NothingToReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Build(nil, nil, now, Options{}), theme.Default()))
+	assert.Equal(t, "Agenda for Wednesday 1 October 2025\n\nNothing overdue, due or recently done.\n", buf.String())
+}
+
+func TestParseSections(t *testing.T) {
+	sections, err := ParseSections(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSections, sections)
+
+	sections, err = ParseSections([]string{"today", " overdue"})
+	require.NoError(t, err)
+	assert.Equal(t, []Section{Today, Overdue}, sections)
+
+	_, err = ParseSections([]string{"later"})
+	assert.EqualError(t, err, `unknown agenda section "later": use overdue, today, week or completed`)
+}
+
+func TestOptions_CompletedSince(t *testing.T) {
+	assert.Equal(t, day(-1), Options{}.CompletedSince(now))
+	assert.Equal(t, day(-7), Options{Weekly: true}.CompletedSince(now))
+}
@@ -0,0 +1,400 @@
+// Package api serves tasks over a local HTTP JSON API for editor plugins and
+// other tools.
+//
+// Routes:
+//
+//	GET    /tasks                 list tasks, filtered by query parameters
+//	POST   /tasks                 create a task
+//	GET    /tasks/{id}            get a task
+//	PATCH  /tasks/{id}            change some fields of a task
+//	DELETE /tasks/{id}            delete a task (?force=true for a protected one)
+//	POST   /tasks/{id}/complete   mark a task complete
+//	GET    /schemas/{name}        JSON Schemas for the bodies above
+//
+// Every task response carries an ETag. PATCH, DELETE and complete honour
+// If-Match, answering 412 Precondition Failed when the task has changed
+// since it was read. Deleting a protected task without force, or completing
+// one that waits on open tasks, answers 409 Conflict.
+//
+// Browsers must not be able to drive the API from other sites: request
+// bodies need a JSON Content-Type, which a cross-site form or simple request
+// cannot send, requests from another Origin are refused, and without a token
+// the Host must be a loopback name so DNS rebinding gets nowhere.
+package api
+
+import (
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+)
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+type Options struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>".
+	Token string
+}
+
+type server struct {
+	repository persistence.TodoRepository
+	options    Options
+	// writes serialises read-check-write sequences so If-Match holds
+	// between the check and the write.
+	writes sync.Mutex
+}
+
+// NewHandler returns the API handler over repository.
+func NewHandler(repository persistence.TodoRepository, options Options) http.Handler {
+	s := &server{repository: repository, options: options}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", s.listTasks)
+	mux.HandleFunc("POST /tasks", s.createTask)
+	mux.HandleFunc("GET /tasks/{id}", s.getTask)
+	mux.HandleFunc("PATCH /tasks/{id}", s.patchTask)
+	mux.HandleFunc("DELETE /tasks/{id}", s.deleteTask)
+	mux.HandleFunc("POST /tasks/{id}/complete", s.completeTask)
+	mux.HandleFunc("GET /schemas/{name}", s.getSchema)
+	return s.guard(s.authenticate(mux))
+}
+
+// guard refuses requests a web page could have made on the user's behalf.
+func (s *server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.options.Token == "" && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("host %q is not a loopback address", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && origin != "http://"+r.Host {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("requests from origin %q are not allowed", origin))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether host, a Host header with or without a
+// port, names this machine.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *server) authenticate(next http.Handler) http.Handler {
+	if s.options.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.options.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="todo"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listTasks answers GET /tasks. Query parameters, all optional:
+//
+//	status       open, done or all (default)
+//	project      exact project name
+//	tag          a tag the task carries
+//	q            case-insensitive substring of the title
+//	due_before   YYYY-MM-DD; only tasks due before that day
+//	sort, desc   as for `todo ls`
+func (s *server) listTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sortKey, err := data.ParseSortKey(query.Get("sort"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	statuses, err := s.repository.GetStatuses()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	filter, err := parseFilter(query, statuses)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tasks, err := s.repository.FindTasks(persistence.ListOptions{Sort: sortKey, Desc: query.Get("desc") == "true"})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		if filter.matches(task) {
+			out = append(out, toJSON(task))
+		}
+	}
+	w.Header().Set("Link", `</schemas/task-list.json>; rel="describedby"`)
+	writeJSON(w, http.StatusOK, map[string]any{"tasks": out})
+}
+
+func (s *server) getTask(w http.ResponseWriter, r *http.Request) {
+	task, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if r.Header.Get("If-None-Match") == etag(task) {
+		w.Header().Set("ETag", etag(task))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeTask(w, http.StatusOK, task)
+}
+
+func (s *server) createTask(w http.ResponseWriter, r *http.Request) {
+	var in taskInput
+	if !decode(w, r, &in) {
+		return
+	}
+	if in.Title == nil {
+		writeError(w, http.StatusUnprocessableEntity, "title is required")
+		return
+	}
+	task, err := in.apply(data.Task{})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	s.writes.Lock()
+	defer s.writes.Unlock()
+	if !s.checkParent(w, task) || !s.checkStatus(w, task) {
+		return
+	}
+	id, err := s.repository.CreateTask(task)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	created, err := s.repository.GetTask(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", id))
+	writeTask(w, http.StatusCreated, created)
+}
+
+func (s *server) patchTask(w http.ResponseWriter, r *http.Request) {
+	var in taskInput
+	if !decode(w, r, &in) {
+		return
+	}
+	s.modify(w, r, func(task data.Task) (data.Task, error) {
+		return in.apply(task)
+	})
+}
+
+func (s *server) completeTask(w http.ResponseWriter, r *http.Request) {
+	s.modify(w, r, func(task data.Task) (data.Task, error) {
+		task.Complete = true
+		return task, nil
+	})
+}
+
+// modify applies change to the task named in the request once its If-Match
+// precondition holds, and answers with the stored result.
+func (s *server) modify(w http.ResponseWriter, r *http.Request, change func(data.Task) (data.Task, error)) {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	task, ok := s.lookup(w, r)
+	if !ok || !preconditionHolds(w, r, task) {
+		return
+	}
+	updated, err := change(task)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if updated.ParentId != task.ParentId && !s.checkParent(w, updated) {
+		return
+	}
+	if updated.Status != task.Status && !s.checkStatus(w, updated) {
+		return
+	}
+	err = s.repository.UpdateTask(updated)
+	var conflict *persistence.ConflictError
+	if errors.As(err, &conflict) {
+		// Another process wrote between our read and write.
+		w.Header().Set("ETag", etag(conflict.Current))
+		writeError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+	var blocked *persistence.BlockedError
+	if errors.As(err, &blocked) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	stored, err := s.repository.GetTask(task.Id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeTask(w, http.StatusOK, stored)
+}
+
+func (s *server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	task, ok := s.lookup(w, r)
+	if !ok || !preconditionHolds(w, r, task) {
+		return
+	}
+	_, err := s.repository.DeleteTasks([]int{task.Id}, r.URL.Query().Get("force") == "true")
+	var protected *persistence.ProtectedError
+	if errors.As(err, &protected) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getSchema(w http.ResponseWriter, r *http.Request) {
+	b, err := schemas.ReadFile("schemas/" + r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "no such schema")
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	_, _ = w.Write(b)
+}
+
+// lookup loads the task named by the {id} path value, answering 400 or 404
+// itself when it cannot.
+func (s *server) lookup(w http.ResponseWriter, r *http.Request) (data.Task, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid task id %q", r.PathValue("id")))
+		return data.Task{}, false
+	}
+	task, err := s.repository.GetTask(id)
+	if errors.Is(err, persistence.ErrTaskNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("task %d not found", id))
+		return data.Task{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return data.Task{}, false
+	}
+	return task, true
+}
+
+func (s *server) checkParent(w http.ResponseWriter, task data.Task) bool {
+	if task.ParentId == 0 {
+		return true
+	}
+	_, err := s.repository.GetTask(task.ParentId)
+	if errors.Is(err, persistence.ErrTaskNotFound) {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("parent task %d not found", task.ParentId))
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *server) checkStatus(w http.ResponseWriter, task data.Task) bool {
+	if task.Status == "" {
+		return true
+	}
+	statuses, err := s.repository.GetStatuses()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if _, ok := data.Statuses(statuses).Find(task.Status); !ok {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("unknown status %q", task.Status))
+		return false
+	}
+	return true
+}
+
+// preconditionHolds checks If-Match against the task's current ETag. A
+// request without If-Match always proceeds.
+func preconditionHolds(w http.ResponseWriter, r *http.Request, task data.Task) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return true
+	}
+	current := etag(task)
+	for _, tag := range strings.Split(match, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+	w.Header().Set("ETag", current)
+	writeError(w, http.StatusPreconditionFailed, fmt.Sprintf("task %d has changed", task.Id))
+	return false
+}
+
+// decode reads a JSON request body into v. Bodies must be sent as
+// application/json, which a cross-site form or simple request cannot do.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeTask(w http.ResponseWriter, status int, task data.Task) {
+	w.Header().Set("ETag", etag(task))
+	w.Header().Set("Link", `</schemas/task.json>; rel="describedby"`)
+	writeJSON(w, status, toJSON(task))
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, options Options) (*httptest.Server, persistence.TodoRepository) {
+	t.Helper()
+	t.Setenv("TODO_DB", filepath.Join(t.TempDir(), "todo.sqlite"))
+	repo := persistence.NewTodoRepository()
+	server := httptest.NewServer(NewHandler(repo, options))
+	t.Cleanup(func() {
+		server.Close()
+		_ = repo.Close()
+	})
+	return server, repo
+}
+
+func do(t *testing.T, server *httptest.Server, method string, path string, body string, headers ...string) *http.Response {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, server.URL+path, reader)
+	require.NoError(t, err)
+	if method != "GET" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		if headers[i] == "Host" {
+			req.Host = headers[i+1]
+			continue
+		}
+		if headers[i+1] == "" {
+			req.Header.Del(headers[i])
+			continue
+		}
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = res.Body.Close() })
+	return res
+}
+
+func decodeBody[T any](t *testing.T, res *http.Response) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&v))
+	return v
+}
+
+func TestCreateAndGetTask(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+
+	res := do(t, server, "POST", "/tasks", `{"title":"Write report","due":"2025-10-05","priority":"a","tags":["work"]}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "/tasks/1", res.Header.Get("Location"))
+	assert.NotEmpty(t, res.Header.Get("ETag"))
+	created := decodeBody[taskJSON](t, res)
+	assert.Equal(t, "Write report", created.Title)
+	assert.Equal(t, "2025-10-05", created.Due)
+	assert.Equal(t, "A", created.Priority)
+	assert.NotEmpty(t, created.CreatedAt)
+
+	res = do(t, server, "GET", "/tasks/1", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, created, decodeBody[taskJSON](t, res))
+
+	res = do(t, server, "GET", "/tasks/1", "", "If-None-Match", res.Header.Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	assert.Equal(t, http.StatusNotFound, do(t, server, "GET", "/tasks/9", "").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "GET", "/tasks/x", "").StatusCode)
+}
+
+func TestCreateTask_Validates(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+
+	for body, status := range map[string]int{
+		`{}`:                             http.StatusUnprocessableEntity,
+		`{"title":"  "}`:                 http.StatusUnprocessableEntity,
+		`{"title":"x","due":"tomorrow"}`: http.StatusUnprocessableEntity,
+		`{"title":"x","priority":"AB"}`:  http.StatusUnprocessableEntity,
+		`{"title":"x","parent_id":42}`:   http.StatusUnprocessableEntity,
+		`{"title":"x","colour":"red"}`:   http.StatusBadRequest,
+		`not json`:                       http.StatusBadRequest,
+	} {
+		res := do(t, server, "POST", "/tasks", body)
+		assert.Equal(t, status, res.StatusCode, body)
+		assert.NotEmpty(t, decodeBody[map[string]string](t, res)["error"], body)
+	}
+}
+
+func TestListTasks_Filters(t *testing.T) {
+	server, repo := newTestServer(t, Options{})
+	for _, task := range []data.Task{
+		{Title: "Pay rent", Project: "home", Tags: []string{"money"}},
+		{Title: "Write report", Project: "work", Complete: true},
+		{Title: "Rent van", Project: "home"},
+	} {
+		_, err := repo.CreateTask(task)
+		require.NoError(t, err)
+	}
+
+	titles := func(query string) []string {
+		res := do(t, server, "GET", "/tasks"+query, "")
+		require.Equal(t, http.StatusOK, res.StatusCode, query)
+		var out []string
+		for _, task := range decodeBody[map[string][]taskJSON](t, res)["tasks"] {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	assert.Len(t, titles(""), 3)
+	assert.Equal(t, []string{"Write report"}, titles("?status=done"))
+	assert.Len(t, titles("?status=todo"), 2)
+	assert.Empty(t, titles("?status=blocked"))
+	assert.Equal(t, []string{"Pay rent", "Rent van"}, titles("?project=home&sort=created"))
+	assert.Equal(t, []string{"Pay rent"}, titles("?tag=money"))
+	assert.Equal(t, []string{"Rent van", "Pay rent"}, titles("?q=RENT&sort=title&desc=true"))
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "GET", "/tasks?status=maybe", "").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "GET", "/tasks?sort=colour", "").StatusCode)
+}
+
+func TestPatchTask_HonoursIfMatch(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+	res := do(t, server, "POST", "/tasks", `{"title":"Draft","due":"2025-10-05","notes":"keep"}`)
+	tag := res.Header.Get("ETag")
+
+	res = do(t, server, "PATCH", "/tasks/1", `{"title":"Final","due":""}`, "If-Match", tag)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	patched := decodeBody[taskJSON](t, res)
+	assert.Equal(t, "Final", patched.Title)
+	assert.Empty(t, patched.Due, "an empty due clears it")
+	assert.Equal(t, "keep", patched.Notes, "absent fields are unchanged")
+	assert.NotEqual(t, tag, res.Header.Get("ETag"))
+
+	res = do(t, server, "PATCH", "/tasks/1", `{"title":"Lost update"}`, "If-Match", tag)
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	res = do(t, server, "GET", "/tasks/1", "")
+	assert.Equal(t, "Final", decodeBody[taskJSON](t, res).Title)
+}
+
+func TestCompleteAndDeleteTask(t *testing.T) {
+	server, repo := newTestServer(t, Options{})
+	res := do(t, server, "POST", "/tasks", `{"title":"Ship"}`)
+	stale := res.Header.Get("ETag")
+
+	res = do(t, server, "POST", "/tasks/1/complete", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	completed := decodeBody[taskJSON](t, res)
+	assert.True(t, completed.Complete)
+	assert.NotEmpty(t, completed.CompletedAt)
+
+	assert.Equal(t, http.StatusPreconditionFailed, do(t, server, "DELETE", "/tasks/1", "", "If-Match", stale).StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(t, server, "DELETE", "/tasks/1", "", "If-Match", res.Header.Get("ETag")).StatusCode)
+	assert.Equal(t, http.StatusNotFound, do(t, server, "DELETE", "/tasks/1", "").StatusCode)
+
+	history, err := repo.GetTaskHistory(1)
+	require.NoError(t, err)
+	assert.Len(t, history, 3, "API changes are recorded like any other")
+}
+
+func TestDeleteProtectedTask(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+	res := do(t, server, "POST", "/tasks", `{"title":"Keep","protected":true}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.True(t, decodeBody[taskJSON](t, res).Protected)
+
+	assert.Equal(t, http.StatusConflict, do(t, server, "DELETE", "/tasks/1", "").StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, server, "GET", "/tasks/1", "").StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(t, server, "DELETE", "/tasks/1?force=true", "").StatusCode)
+}
+
+func TestTaskStatus(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+	res := do(t, server, "POST", "/tasks", `{"title":"Review"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "todo", decodeBody[taskJSON](t, res).Status)
+
+	res = do(t, server, "PATCH", "/tasks/1", `{"status":"done"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, decodeBody[taskJSON](t, res).Complete, "a terminal status completes the task")
+
+	res = do(t, server, "PATCH", "/tasks/1", `{"complete":false}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "todo", decodeBody[taskJSON](t, res).Status)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, server, "PATCH", "/tasks/1", `{"status":"someday"}`).StatusCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, server, "POST", "/tasks", `{"title":"x","status":"someday"}`).StatusCode)
+}
+
+func TestCompleteBlockedTask(t *testing.T) {
+	server, repo := newTestServer(t, Options{})
+	ids, err := repo.UpsertTasks([]data.Task{{Title: "Design"}, {Title: "Build"}})
+	require.NoError(t, err)
+	require.NoError(t, repo.LinkTasks(ids[0], []int{ids[1]}))
+
+	res := do(t, server, "POST", fmt.Sprintf("/tasks/%d/complete", ids[1]), "")
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, server, "POST", fmt.Sprintf("/tasks/%d/complete", ids[0]), "").StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, server, "POST", fmt.Sprintf("/tasks/%d/complete", ids[1]), "").StatusCode)
+}
+
+func TestTokenAuth(t *testing.T) {
+	server, _ := newTestServer(t, Options{Token: "s3cret"})
+
+	res := do(t, server, "GET", "/tasks", "")
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Contains(t, res.Header.Get("WWW-Authenticate"), "Bearer")
+	assert.Equal(t, http.StatusUnauthorized, do(t, server, "GET", "/tasks", "", "Authorization", "Bearer wrong").StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, server, "GET", "/tasks", "", "Authorization", "Bearer s3cret").StatusCode)
+}
+
+func TestSchemas_DescribeTheWireFormat(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+
+	properties := func(name string) []string {
+		res := do(t, server, "GET", "/schemas/"+name, "")
+		require.Equal(t, http.StatusOK, res.StatusCode, name)
+		assert.Equal(t, "application/schema+json", res.Header.Get("Content-Type"))
+		schema := decodeBody[struct {
+			Properties map[string]any `json:"properties"`
+		}](t, res)
+		var names []string
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	assert.Equal(t, jsonFields(taskJSON{}), properties("task.json"))
+	assert.Equal(t, jsonFields(taskInput{}), properties("task-create.json"))
+	assert.Equal(t, jsonFields(taskInput{}), properties("task-patch.json"))
+	assert.Equal(t, []string{"tasks"}, properties("task-list.json"))
+	assert.Equal(t, http.StatusNotFound, do(t, server, "GET", "/schemas/nope.json", "").StatusCode)
+}
+
+func jsonFields(v any) []string {
+	var names []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBodiesMustBeJSON(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+	require.Equal(t, http.StatusCreated, do(t, server, "POST", "/tasks", `{"title":"Pay rent"}`).StatusCode)
+	require.Equal(t, http.StatusCreated, do(t, server, "POST", "/tasks", `{"title":"Book dentist"}`).StatusCode)
+
+	res := do(t, server, "POST", "/tasks", `{"title":"Forged"}`, "Content-Type", "text/plain")
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+	res = do(t, server, "PATCH", "/tasks/1", `{"title":"Forged"}`, "Content-Type", "application/x-www-form-urlencoded")
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+	res = do(t, server, "PATCH", "/tasks/1", `{"title":"Pay the rent"}`, "Content-Type", "application/json; charset=utf-8")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = do(t, server, "POST", "/tasks/1/complete", "", "Content-Type", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode, "requests without a body need no Content-Type")
+	res = do(t, server, "DELETE", "/tasks/2", "", "Content-Type", "")
+	assert.Equal(t, http.StatusNoContent, res.StatusCode, "a plain DELETE, as curl -X DELETE sends")
+	res = do(t, server, "POST", "/tasks/1/complete", "", "Content-Type", "", "Origin", "https://evil.example")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode, "a cross-site POST carries its Origin")
+}
+
+func TestForeignOriginsAreRefused(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+
+	res := do(t, server, "GET", "/tasks", "", "Origin", "https://evil.example")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	res = do(t, server, "POST", "/tasks", `{"title":"Forged"}`, "Origin", "http://localhost:3000")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode, "another local site is still another origin")
+
+	res = do(t, server, "GET", "/tasks", "", "Origin", server.URL)
+	assert.Equal(t, http.StatusOK, res.StatusCode, "the server's own origin is allowed")
+}
+
+func TestHostMustBeLoopbackWithoutToken(t *testing.T) {
+	server, _ := newTestServer(t, Options{})
+
+	res := do(t, server, "GET", "/tasks", "", "Host", "rebound.evil.example:7777")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	for _, host := range []string{"localhost:7777", "127.0.0.1", "[::1]:7777"} {
+		assert.Equal(t, http.StatusOK, do(t, server, "GET", "/tasks", "", "Host", host).StatusCode, host)
+	}
+
+	server, _ = newTestServer(t, Options{Token: "s3cret"})
+	res = do(t, server, "GET", "/tasks", "", "Host", "todo.example.com", "Authorization", "Bearer s3cret")
+	assert.Equal(t, http.StatusOK, res.StatusCode, "with a token the server may be reached by name")
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+type filter struct {
+	status    string
+	project   string
+	tag       string
+	query     string
+	dueBefore time.Time
+}
+
+// parseFilter reads the list filters from query. status is open, done, all
+// or the name of one of statuses.
+func parseFilter(query url.Values, statuses data.Statuses) (filter, error) {
+	f := filter{
+		status:  query.Get("status"),
+		project: query.Get("project"),
+		tag:     query.Get("tag"),
+		query:   strings.ToLower(query.Get("q")),
+	}
+	switch f.status {
+	case "", "all", "open", "done":
+	default:
+		if _, ok := statuses.Find(f.status); !ok {
+			return f, fmt.Errorf("status %q must be open, done, all or a task status", f.status)
+		}
+	}
+	if before := query.Get("due_before"); before != "" {
+		due, err := time.Parse(time.DateOnly, before)
+		if err != nil {
+			return f, fmt.Errorf("due_before %q must be a YYYY-MM-DD date", before)
+		}
+		f.dueBefore = due
+	}
+	return f, nil
+}
+
+func (f filter) matches(task data.Task) bool {
+	switch {
+	case f.status == "open" && task.Complete,
+		f.status == "done" && !task.Complete,
+		!slices.Contains([]string{"", "all", "open", "done"}, f.status) && task.Status != f.status,
+		f.project != "" && task.Project != f.project,
+		f.tag != "" && !slices.Contains(task.Tags, f.tag),
+		f.query != "" && !strings.Contains(strings.ToLower(task.Title), f.query),
+		!f.dueBefore.IsZero() && (task.DueDate.IsZero() || !task.DueDate.Before(f.dueBefore)):
+		return false
+	}
+	return true
+}
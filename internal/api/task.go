@@ -0,0 +1,148 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+// taskJSON is the wire representation of a task, described by
+// schemas/task.json.
+type taskJSON struct {
+	Id          int      `json:"id"`
+	Title       string   `json:"title"`
+	Complete    bool     `json:"complete"`
+	Status      string   `json:"status"`
+	Protected   bool     `json:"protected,omitempty"`
+	Due         string   `json:"due,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Project     string   `json:"project,omitempty"`
+	Tags        []string `json:"tags"`
+	Notes       string   `json:"notes,omitempty"`
+	ParentId    int      `json:"parent_id,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+	CompletedAt string   `json:"completed_at,omitempty"`
+}
+
+func toJSON(task data.Task) taskJSON {
+	out := taskJSON{
+		Id:          task.Id,
+		Title:       task.Title,
+		Complete:    task.Complete,
+		Status:      task.Status,
+		Protected:   task.Protected,
+		Priority:    task.Priority,
+		Project:     task.Project,
+		Tags:        task.Tags,
+		Notes:       task.Notes,
+		ParentId:    task.ParentId,
+		CreatedAt:   timestamp(task.CreatedAt),
+		UpdatedAt:   timestamp(task.UpdatedAt),
+		CompletedAt: timestamp(task.CompletedAt),
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if !task.DueDate.IsZero() {
+		out.Due = task.DueDate.Format(time.DateOnly)
+	}
+	return out
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// etag identifies one state of a task. Any change to a field, including the
+// repository's updated_at, gives a new tag.
+func etag(task data.Task) string {
+	b, _ := json.Marshal(toJSON(task))
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf(`"%x"`, sum[:8])
+}
+
+// taskInput is the body of a create or patch request, described by
+// schemas/task-create.json and schemas/task-patch.json. Absent fields are
+// left unchanged by a patch; an empty due clears the due date. A status
+// takes precedence over complete when both are given.
+type taskInput struct {
+	Title     *string   `json:"title"`
+	Complete  *bool     `json:"complete"`
+	Status    *string   `json:"status"`
+	Protected *bool     `json:"protected"`
+	Due       *string   `json:"due"`
+	Priority  *string   `json:"priority"`
+	Project   *string   `json:"project"`
+	Tags      *[]string `json:"tags"`
+	Notes     *string   `json:"notes"`
+	ParentId  *int      `json:"parent_id"`
+}
+
+// apply writes the fields present in in onto task.
+func (in taskInput) apply(task data.Task) (data.Task, error) {
+	if in.Title != nil {
+		title := strings.TrimSpace(*in.Title)
+		if title == "" {
+			return task, fmt.Errorf("title must not be empty")
+		}
+		task.Title = title
+	}
+	if in.Complete != nil {
+		task.Complete = *in.Complete
+	}
+	if in.Status != nil {
+		task.Status = *in.Status
+	}
+	if in.Protected != nil {
+		task.Protected = *in.Protected
+	}
+	if in.Due != nil {
+		task.DueDate = time.Time{}
+		if *in.Due != "" {
+			due, err := time.Parse(time.DateOnly, *in.Due)
+			if err != nil {
+				return task, fmt.Errorf("due %q must be a YYYY-MM-DD date", *in.Due)
+			}
+			task.DueDate = due
+		}
+	}
+	if in.Priority != nil {
+		priority, err := data.ParsePriority(*in.Priority)
+		if err != nil {
+			return task, err
+		}
+		task.Priority = priority
+	}
+	if in.Project != nil {
+		task.Project = *in.Project
+	}
+	if in.Tags != nil {
+		task.Tags = nil
+		for _, tag := range *in.Tags {
+			if strings.Contains(tag, ",") {
+				return task, fmt.Errorf("tag %q must not contain a comma", tag)
+			}
+			if tag != "" {
+				task.Tags = append(task.Tags, tag)
+			}
+		}
+	}
+	if in.Notes != nil {
+		task.Notes = *in.Notes
+	}
+	if in.ParentId != nil {
+		if *in.ParentId < 0 || (task.Id != 0 && *in.ParentId == task.Id) {
+			return task, fmt.Errorf("parent_id %d is not a valid parent", *in.ParentId)
+		}
+		task.ParentId = *in.ParentId
+	}
+	return task, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config holds user preferences read from a JSON file. Every field is
+// optional; the zero value means "use the built-in default".
+type Config struct {
+	// Theme names a built-in theme (dark, light, high-contrast) or one of Themes.
+	Theme string `json:"theme"`
+	// Themes defines user themes as colour overrides keyed by role. The
+	// special "base" key names the built-in theme to start from.
+	Themes map[string]map[string]string `json:"themes"`
+	// Keys configures the keys of the interactive views.
+	Keys Keys `json:"keys"`
+	// List configures the interactive list view.
+	List List `json:"list"`
+	// Agenda configures `todo agenda`.
+	Agenda Agenda `json:"agenda"`
+	// Server configures `todo serve`.
+	Server Server `json:"server"`
+}
+
+type Keys struct {
+	// Preset names a built-in key map: default, vim or emacs.
+	Preset string `json:"preset"`
+	// Bindings rebinds actions, such as "toggle" or "quit", to the keys
+	// listed.
+	Bindings map[string][]string `json:"bindings"`
+}
+
+type List struct {
+	// ConfirmDelete says when deleting a task asks first: always (the
+	// default), never, or incomplete for tasks that are not complete.
+	ConfirmDelete string `json:"confirm_delete"`
+}
+
+type Agenda struct {
+	// Sections lists the sections to print, in order: overdue, today, week
+	// and completed. Empty prints them all.
+	Sections []string `json:"sections"`
+}
+
+type Server struct {
+	// Token, when set, must be sent by API clients as a bearer token.
+	Token string `json:"token"`
+}
+
+func Path() string {
+	if e := os.Getenv("TODO_CONFIG"); e != "" {
+		return e
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(dir, "todo", "config.json")
+}
+
+// Load reads the config file at Path. A missing file is not an error and
+// yields the default configuration.
+func Load() (Config, error) {
+	return LoadFile(Path())
+}
+
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
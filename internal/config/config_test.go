@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath_UsesEnvOverride(t *testing.T) {
+	t.Setenv("TODO_CONFIG", "/tmp/custom.json")
+	assert.Equal(t, "/tmp/custom.json", Path())
+}
+
+func TestLoad_MissingFile_ReturnsDefaults(t *testing.T) {
+	t.Setenv("TODO_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, Config{}, cfg)
+}
+
+func TestLoadFile_ParsesThemes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"theme":"mine","themes":{"mine":{"base":"light","overdue":"#ff0000"}}}`), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", cfg.Theme)
+	assert.Equal(t, "#ff0000", cfg.Themes["mine"]["overdue"])
+}
+
+func TestLoadFile_ParsesKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"keys":{"preset":"vim","bindings":{"toggle":["c","x"]}}}`), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Keys{Preset: "vim", Bindings: map[string][]string{"toggle": {"c", "x"}}}, cfg.Keys)
+}
+
+func TestLoadFile_ParsesList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"list":{"confirm_delete":"incomplete"}}`), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "incomplete", cfg.List.ConfirmDelete)
+}
+
+func TestLoadFile_ParsesAgenda(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"agenda":{"sections":["today","overdue"]}}`), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"today", "overdue"}, cfg.Agenda.Sections)
+}
+
+func TestLoadFile_InvalidJSON_ReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0o600))
+
+	_, err := LoadFile(path)
+	assert.ErrorContains(t, err, path)
+}
@@ -1,10 +1,71 @@
 package data
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Task struct {
 	Id       int
 	Title    string
 	Complete bool
+	// Status is where the task is in the workflow. The repository keeps
+	// Complete true exactly when Status is terminal; see
+	// Statuses.Reconcile.
+	Status   string
 	DueDate  time.Time
+	Priority string
+	Project  string
+	Tags     []string
+	Notes    string
+	// UID identifies a task imported from another system (an iCalendar UID or
+	// Taskwarrior UUID) so re-imports update it. Empty for native tasks.
+	UID string
+	// ParentId is the task this one is a subtask of, or 0 for a top-level
+	// task.
+	ParentId int
+	// Version counts the stored task's updates. An update carrying a Version
+	// only applies while the stored task is still at that version; zero
+	// skips the check.
+	Version int
+	// Protected tasks are refused by deletes that are not forced.
+	Protected bool
+
+	// CreatedAt, UpdatedAt and CompletedAt are maintained by the repository.
+	// CompletedAt is zero while the task is open.
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt time.Time
+}
+
+// ParsePriority normalises a todo.txt style priority: a single letter from A
+// (highest) to Z, or the empty string for none.
+func ParsePriority(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	p := strings.ToUpper(s)
+	if len(p) != 1 || p[0] < 'A' || p[0] > 'Z' {
+		return "", fmt.Errorf("priority %q must be a single letter A-Z", s)
+	}
+	return p, nil
+}
+
+// PendingParent is a ParentId referring to the task at index in the same
+// batch, for subtasks read alongside a parent that has no Id yet. It is
+// resolved to the parent's Id when the batch is stored.
+func PendingParent(index int) int {
+	return -index - 1
+}
+
+// PendingIndex reports the batch index a PendingParent refers to.
+func PendingIndex(parentId int) (int, bool) {
+	if parentId >= 0 {
+		return 0, false
+	}
+	return -parentId - 1, true
 }
+
+// TimestampLayout is how audit timestamps are shown to users, in local time.
+const TimestampLayout = "2006-01-02 15:04"
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Dependency records that Blocker has to be completed before Blocked can
+// be, along with whether each task already is.
+type Dependency struct {
+	Blocker         int
+	Blocked         int
+	BlockerComplete bool
+	BlockedComplete bool
+}
+
+// Dependencies are the links between tasks.
+type Dependencies []Dependency
+
+// BlockedBy returns the ids of the open tasks id waits on, in order.
+func (d Dependencies) BlockedBy(id int) []int {
+	var ids []int
+	for _, dep := range d {
+		if dep.Blocked == id && !dep.BlockerComplete {
+			ids = append(ids, dep.Blocker)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Blocking returns the ids of the open tasks waiting on id while it is
+// open, in order.
+func (d Dependencies) Blocking(id int) []int {
+	var ids []int
+	for _, dep := range d {
+		if dep.Blocker == id && !dep.BlockerComplete && !dep.BlockedComplete {
+			ids = append(ids, dep.Blocked)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Path returns the tasks from from to to following blocker to blocked links,
+// both ends included, or nil when to does not wait on from. Linking to as a
+// blocker of from would close a cycle along it.
+func (d Dependencies) Path(from int, to int) []int {
+	previous := map[int]int{from: from}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == to {
+			path := []int{to}
+			for id != from {
+				id = previous[id]
+				path = append(path, id)
+			}
+			slices.Reverse(path)
+			return path
+		}
+		for _, dep := range d {
+			if _, seen := previous[dep.Blocked]; dep.Blocker == id && !seen {
+				previous[dep.Blocked] = id
+				queue = append(queue, dep.Blocked)
+			}
+		}
+	}
+	return nil
+}
+
+// JoinIds lists ids for messages: "2, 3".
+func JoinIds(ids []int) string {
+	return joinIds(ids, ", ")
+}
+
+// JoinPath lists the tasks along a path of links: "2 → 3".
+func JoinPath(ids []int) string {
+	return joinIds(ids, " → ")
+}
+
+func joinIds(ids []int, sep string) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 1 blocks 2 and 3, 2 blocks 4, and 3 blocks 4 but is complete.
+var deps = Dependencies{
+	{Blocker: 1, Blocked: 2},
+	{Blocker: 1, Blocked: 3},
+	{Blocker: 2, Blocked: 4},
+	{Blocker: 3, Blocked: 4, BlockerComplete: true},
+}
+
+func TestDependencies_BlockedByAndBlocking(t *testing.T) {
+	assert.Equal(t, []int{2}, deps.BlockedBy(4), "complete blockers no longer block")
+	assert.Empty(t, deps.BlockedBy(1))
+	assert.Equal(t, []int{2, 3}, deps.Blocking(1))
+	assert.Empty(t, deps.Blocking(3))
+}
+
+func TestDependencies_Path(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 4}, deps.Path(1, 4))
+	assert.Equal(t, []int{3, 4}, deps.Path(3, 4), "complete tasks still count towards cycles")
+	assert.Equal(t, []int{2}, deps.Path(2, 2))
+	assert.Nil(t, deps.Path(4, 1))
+	assert.Nil(t, deps.Path(2, 3))
+}
+
+func TestJoinIds(t *testing.T) {
+	assert.Equal(t, "2, 3", JoinIds([]int{2, 3}))
+	assert.Equal(t, "", JoinIds(nil))
+	assert.Equal(t, "3 → 1 → 3", JoinPath([]int{3, 1, 3}))
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+type Urgency int
+
+const (
+	UrgencyNone Urgency = iota
+	UrgencyLater
+	UrgencySoon
+	UrgencyToday
+	UrgencyOverdue
+)
+
+// SoonDays is how many days ahead a due date counts as "due soon".
+const SoonDays = 3
+
+// DaysUntil returns the number of calendar days from now until due; negative
+// when due is in the past.
+func DaysUntil(due time.Time, now time.Time) int {
+	today := StartOfDay(now)
+	day := CalendarDay(due, now.Location())
+	return int(day.Sub(today).Round(24*time.Hour) / (24 * time.Hour))
+}
+
+// RelativeDue renders a due date relative to now: "overdue 2d", "today" or
+// "in 3d". Tasks without a due date render as the empty string.
+func RelativeDue(due time.Time, now time.Time) string {
+	if due.IsZero() {
+		return ""
+	}
+	switch days := DaysUntil(due, now); {
+	case days < 0:
+		return fmt.Sprintf("overdue %dd", -days)
+	case days == 0:
+		return "today"
+	default:
+		return fmt.Sprintf("in %dd", days)
+	}
+}
+
+// TaskUrgency classifies how pressing a task is. Completed and undated tasks
+// are never urgent.
+func TaskUrgency(task Task, now time.Time) Urgency {
+	if task.Complete || task.DueDate.IsZero() {
+		return UrgencyNone
+	}
+	switch days := DaysUntil(task.DueDate, now); {
+	case days < 0:
+		return UrgencyOverdue
+	case days == 0:
+		return UrgencyToday
+	case days <= SoonDays:
+		return UrgencySoon
+	default:
+		return UrgencyLater
+	}
+}
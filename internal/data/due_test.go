@@ -0,0 +1,34 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeDue(t *testing.T) {
+	assert.Equal(t, "overdue 2d", RelativeDue(day(-2), now))
+	assert.Equal(t, "today", RelativeDue(day(0), now))
+	assert.Equal(t, "in 3d", RelativeDue(day(3), now))
+	assert.Equal(t, "", RelativeDue(time.Time{}, now))
+}
+
+func TestDaysUntil_SpansDaylightSavingChange(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	before := time.Date(2025, time.October, 25, 12, 0, 0, 0, london)
+	due := time.Date(2025, time.October, 27, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2, DaysUntil(due, before))
+}
+
+func TestTaskUrgency(t *testing.T) {
+	assert.Equal(t, UrgencyOverdue, TaskUrgency(Task{DueDate: day(-1)}, now))
+	assert.Equal(t, UrgencyToday, TaskUrgency(Task{DueDate: day(0)}, now))
+	assert.Equal(t, UrgencySoon, TaskUrgency(Task{DueDate: day(SoonDays)}, now))
+	assert.Equal(t, UrgencyLater, TaskUrgency(Task{DueDate: day(SoonDays + 1)}, now))
+	assert.Equal(t, UrgencyNone, TaskUrgency(Task{DueDate: day(-1), Complete: true}, now))
+	assert.Equal(t, UrgencyNone, TaskUrgency(Task{}, now))
+}
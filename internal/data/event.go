@@ -0,0 +1,101 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type EventKind string
+
+const (
+	EventCreated EventKind = "created"
+	EventUpdated EventKind = "updated"
+	EventDeleted EventKind = "deleted"
+)
+
+// Event records one change to a task. Created events list every initial
+// value as a change from empty, deleted events every final value as a change
+// to empty.
+type Event struct {
+	Id      int
+	TaskId  int
+	Kind    EventKind
+	At      time.Time
+	Changes []Change
+}
+
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Old == "":
+		return fmt.Sprintf("%s: %q", c.Field, c.New)
+	case c.New == "":
+		return fmt.Sprintf("%s: %q (removed)", c.Field, c.Old)
+	default:
+		return fmt.Sprintf("%s: %q → %q", c.Field, c.Old, c.New)
+	}
+}
+
+// DiffTasks lists the user-editable fields that differ between old and new.
+// Repository-maintained fields (id, version and timestamps) are ignored.
+func DiffTasks(old Task, new Task) []Change {
+	var changes []Change
+	for _, f := range fieldValues(old) {
+		n := fieldValue(new, f.Field)
+		if f.New != n {
+			changes = append(changes, Change{Field: f.Field, Old: f.New, New: n})
+		}
+	}
+	return changes
+}
+
+func fieldValue(task Task, field string) string {
+	for _, f := range fieldValues(task) {
+		if f.Field == field {
+			return f.New
+		}
+	}
+	return ""
+}
+
+// fieldValues renders every tracked field of task as a Change from empty.
+func fieldValues(task Task) []Change {
+	due := ""
+	if !task.DueDate.IsZero() {
+		due = task.DueDate.Format(time.DateOnly)
+	}
+	parent := ""
+	if task.ParentId > 0 {
+		parent = strconv.Itoa(task.ParentId)
+	}
+	return []Change{
+		{Field: "title", New: task.Title},
+		{Field: "complete", New: strconv.FormatBool(task.Complete)},
+		{Field: "status", New: task.Status},
+		{Field: "due", New: due},
+		{Field: "priority", New: task.Priority},
+		{Field: "project", New: task.Project},
+		{Field: "tags", New: strings.Join(task.Tags, ",")},
+		{Field: "notes", New: task.Notes},
+		{Field: "parent", New: parent},
+		{Field: "protected", New: strconv.FormatBool(task.Protected)},
+	}
+}
+
+// CreatedChanges describes a new task as changes from empty, skipping
+// fields left unset.
+func CreatedChanges(task Task) []Change {
+	return DiffTasks(Task{}, task)
+}
+
+// DeletedChanges describes a removed task as changes to empty.
+func DeletedChanges(task Task) []Change {
+	return DiffTasks(task, Task{})
+}
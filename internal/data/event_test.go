@@ -0,0 +1,45 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTasks_ListsChangedFieldsOnly(t *testing.T) {
+	old := Task{Id: 1, Title: "Old", DueDate: day(0), Tags: []string{"a"}}
+	new := old
+	new.Title = "New"
+	new.Complete = true
+	new.Tags = []string{"a", "b"}
+	new.UpdatedAt = now
+
+	assert.Equal(t, []Change{
+		{Field: "title", Old: "Old", New: "New"},
+		{Field: "complete", Old: "false", New: "true"},
+		{Field: "tags", Old: "a", New: "a,b"},
+	}, DiffTasks(old, new))
+	assert.Empty(t, DiffTasks(old, old))
+}
+
+func TestCreatedAndDeletedChanges(t *testing.T) {
+	task := Task{Title: "T", DueDate: day(1), Project: "home"}
+
+	assert.Equal(t, []Change{
+		{Field: "title", New: "T"},
+		{Field: "due", New: "2025-10-02"},
+		{Field: "project", New: "home"},
+	}, CreatedChanges(task))
+
+	assert.Equal(t, []Change{
+		{Field: "title", Old: "T"},
+		{Field: "due", Old: "2025-10-02"},
+		{Field: "project", Old: "home"},
+	}, DeletedChanges(task))
+}
+
+func TestChange_String(t *testing.T) {
+	assert.Equal(t, `title: "T"`, Change{Field: "title", New: "T"}.String())
+	assert.Equal(t, `title: "T" (removed)`, Change{Field: "title", Old: "T"}.String())
+	assert.Equal(t, `complete: "false" → "true"`, Change{Field: "complete", Old: "false", New: "true"}.String())
+}
@@ -0,0 +1,35 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatTask renders task as one plain line for printed lists: its check
+// box, id and title, then priority, project, tags and protection, as in
+// "[ ] 4 - Pay rent (A) +home #bills". Callers append when it is due or was
+// done in their own way.
+func FormatTask(task Task) string {
+	check := " "
+	if task.Complete {
+		check = "x"
+	}
+	line := fmt.Sprintf("[%s] %d - %s", check, task.Id, task.Title)
+	var extra []string
+	if task.Priority != "" {
+		extra = append(extra, "("+task.Priority+")")
+	}
+	if task.Project != "" {
+		extra = append(extra, "+"+task.Project)
+	}
+	for _, tag := range task.Tags {
+		extra = append(extra, "#"+tag)
+	}
+	if task.Protected {
+		extra = append(extra, "[protected]")
+	}
+	if len(extra) > 0 {
+		line += " " + strings.Join(extra, " ")
+	}
+	return line
+}
@@ -0,0 +1,14 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTask(t *testing.T) {
+	assert.Equal(t, "[ ] 4 - Pay rent", FormatTask(Task{Id: 4, Title: "Pay rent"}))
+	assert.Equal(t, "[x] 4 - Pay rent (A) +home #bills #monthly [protected]", FormatTask(Task{
+		Id: 4, Title: "Pay rent", Complete: true, Priority: "A", Project: "home", Tags: []string{"bills", "monthly"}, Protected: true,
+	}))
+}
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type GroupBy string
+
+const (
+	GroupByNone    GroupBy = "none"
+	GroupByDue     GroupBy = "due"
+	GroupByProject GroupBy = "project"
+	GroupByTag     GroupBy = "tag"
+)
+
+var GroupBys = []GroupBy{GroupByNone, GroupByDue, GroupByProject, GroupByTag}
+
+const (
+	BucketOverdue  = "Overdue"
+	BucketToday    = "Today"
+	BucketTomorrow = "Tomorrow"
+	BucketThisWeek = "This Week"
+	BucketLater    = "Later"
+	BucketNoDate   = "No date"
+
+	NoProject = "No project"
+	NoTag     = "No tag"
+)
+
+var dueBuckets = []string{BucketOverdue, BucketToday, BucketTomorrow, BucketThisWeek, BucketLater, BucketNoDate}
+
+type Group struct {
+	Name  string
+	Tasks []Task
+}
+
+func ParseGroupBy(s string) (GroupBy, error) {
+	if s == "" {
+		return GroupByNone, nil
+	}
+	for _, g := range GroupBys {
+		if strings.EqualFold(s, string(g)) {
+			return g, nil
+		}
+	}
+	return "", fmt.Errorf("unknown grouping %q (want one of %s)", s, joinKeys(GroupBys))
+}
+
+// GroupTasks splits tasks into named groups, keeping the incoming order of
+// tasks within each group. Due buckets are returned in chronological order,
+// project and tag groups alphabetically with the ungrouped tasks last. A task
+// is grouped under its first tag only so that it is listed once.
+func GroupTasks(tasks []Task, by GroupBy, now time.Time) []Group {
+	if by == GroupByNone || by == "" {
+		return []Group{{Name: "Tasks", Tasks: tasks}}
+	}
+
+	byName := map[string][]Task{}
+	for _, task := range tasks {
+		name := groupName(task, by, now)
+		byName[name] = append(byName[name], task)
+	}
+
+	var names []string
+	switch by {
+	case GroupByDue:
+		names = dueBuckets
+	default:
+		fallback := NoProject
+		if by == GroupByTag {
+			fallback = NoTag
+		}
+		for name := range byName {
+			if name != fallback {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		names = append(names, fallback)
+	}
+
+	groups := make([]Group, 0, len(byName))
+	for _, name := range names {
+		if ts, ok := byName[name]; ok {
+			groups = append(groups, Group{Name: name, Tasks: ts})
+		}
+	}
+	return groups
+}
+
+func groupName(task Task, by GroupBy, now time.Time) string {
+	switch by {
+	case GroupByDue:
+		return DueBucket(task.DueDate, now)
+	case GroupByProject:
+		if task.Project == "" {
+			return NoProject
+		}
+		return task.Project
+	case GroupByTag:
+		if len(task.Tags) == 0 {
+			return NoTag
+		}
+		return task.Tags[0]
+	}
+	return ""
+}
+
+// DueBucket names the relative due window a date falls into. Weeks start on
+// Monday, so "This Week" covers the days after tomorrow up to Sunday.
+func DueBucket(due time.Time, now time.Time) string {
+	if due.IsZero() {
+		return BucketNoDate
+	}
+	today := StartOfDay(now)
+	day := CalendarDay(due, now.Location())
+	tomorrow := today.AddDate(0, 0, 1)
+	nextWeek := NextMonday(today)
+
+	switch {
+	case day.Before(today):
+		return BucketOverdue
+	case day.Equal(today):
+		return BucketToday
+	case day.Equal(tomorrow):
+		return BucketTomorrow
+	case day.Before(nextWeek):
+		return BucketThisWeek
+	default:
+		return BucketLater
+	}
+}
+
+// NextMonday returns the first Monday after today.
+func NextMonday(today time.Time) time.Time {
+	daysToMonday := (8 - int(today.Weekday())) % 7
+	if daysToMonday == 0 {
+		daysToMonday = 7
+	}
+	return today.AddDate(0, 0, daysToMonday)
+}
+
+func StartOfDay(t time.Time) time.Time {
+	return CalendarDay(t, t.Location())
+}
+
+// CalendarDay returns midnight in loc of the calendar date t carries. Due
+// dates are stored as UTC midnights, so converting them with In would shift
+// them onto the previous day west of Greenwich.
+func CalendarDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,99 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Wednesday 1 October 2025, mid-morning.
+var now = time.Date(2025, time.October, 1, 10, 30, 0, 0, time.UTC)
+
+func day(offset int) time.Time {
+	return time.Date(2025, time.October, 1+offset, 0, 0, 0, 0, time.UTC)
+}
+
+func names(groups []Group) []string {
+	var out []string
+	for _, g := range groups {
+		out = append(out, g.Name)
+	}
+	return out
+}
+
+func TestDueBucket(t *testing.T) {
+	assert.Equal(t, BucketOverdue, DueBucket(day(-1), now))
+	assert.Equal(t, BucketToday, DueBucket(day(0), now))
+	assert.Equal(t, BucketTomorrow, DueBucket(day(1), now))
+	assert.Equal(t, BucketThisWeek, DueBucket(day(4), now), "Sunday closes the week")
+	assert.Equal(t, BucketLater, DueBucket(day(5), now), "next Monday starts a new week")
+	assert.Equal(t, BucketNoDate, DueBucket(time.Time{}, now))
+}
+
+func TestDueBucket_UsesCalendarDateOfUTCMidnights(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	localNow := time.Date(2025, time.October, 1, 9, 0, 0, 0, ny)
+	assert.Equal(t, BucketToday, DueBucket(day(0), localNow))
+}
+
+func TestGroupTasks_None_ReturnsSingleGroup(t *testing.T) {
+	tasks := []Task{{Id: 1}, {Id: 2}}
+	groups := GroupTasks(tasks, GroupByNone, now)
+	if assert.Len(t, groups, 1) {
+		assert.Equal(t, tasks, groups[0].Tasks)
+	}
+}
+
+func TestGroupTasks_Due_OrdersBucketsChronologically(t *testing.T) {
+	tasks := []Task{
+		{Id: 1, DueDate: day(10)},
+		{Id: 2, DueDate: day(-3)},
+		{Id: 3},
+		{Id: 4, DueDate: day(0)},
+		{Id: 5, DueDate: day(-1)},
+	}
+	groups := GroupTasks(tasks, GroupByDue, now)
+	assert.Equal(t, []string{BucketOverdue, BucketToday, BucketLater, BucketNoDate}, names(groups))
+	assert.Equal(t, []Task{tasks[1], tasks[4]}, groups[0].Tasks, "order within a bucket is preserved")
+}
+
+func TestGroupTasks_Project_SortsNamesWithUngroupedLast(t *testing.T) {
+	tasks := []Task{
+		{Id: 1, Project: "work"},
+		{Id: 2},
+		{Id: 3, Project: "home"},
+	}
+	groups := GroupTasks(tasks, GroupByProject, now)
+	assert.Equal(t, []string{"home", "work", NoProject}, names(groups))
+}
+
+func TestGroupTasks_Tag_UsesFirstTag(t *testing.T) {
+	tasks := []Task{
+		{Id: 1, Tags: []string{"urgent", "work"}},
+		{Id: 2, Tags: []string{"work"}},
+		{Id: 3},
+	}
+	groups := GroupTasks(tasks, GroupByTag, now)
+	assert.Equal(t, []string{"urgent", "work", NoTag}, names(groups))
+	assert.Len(t, groups[0].Tasks, 1)
+}
+
+func TestParseSortKeyAndGroupBy(t *testing.T) {
+	k, err := ParseSortKey("Priority")
+	assert.NoError(t, err)
+	assert.Equal(t, SortByPriority, k)
+
+	_, err = ParseSortKey("size")
+	assert.Error(t, err)
+
+	g, err := ParseGroupBy("")
+	assert.NoError(t, err)
+	assert.Equal(t, GroupByNone, g)
+
+	_, err = ParseGroupBy("colour")
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseSince turns a look-back window into the instant it starts at. It
+// accepts a day count ("7d"), a week count ("2w"), any time.ParseDuration
+// value ("36h") or a calendar date ("2025-10-01", local midnight). Day and
+// week windows count calendar days back from the start of today.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if date, err := time.ParseInLocation(time.DateOnly, s, now.Location()); err == nil {
+		return date, nil
+	}
+	if n, unit, ok := splitCount(s); ok {
+		switch unit {
+		case "d":
+			return StartOfDay(now).AddDate(0, 0, -n), nil
+		case "w":
+			return StartOfDay(now).AddDate(0, 0, -7*n), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid window %q: use e.g. 7d, 2w, 36h or YYYY-MM-DD", s)
+	}
+	return now.Add(-d), nil
+}
+
+func splitCount(s string) (int, string, bool) {
+	if len(s) < 2 {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, "", false
+	}
+	return n, s[len(s)-1:], true
+}
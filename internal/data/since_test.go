@@ -0,0 +1,29 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSince(t *testing.T) {
+	got, err := ParseSince("7d", now)
+	assert.NoError(t, err)
+	assert.Equal(t, day(-7), got)
+
+	got, err = ParseSince("2w", now)
+	assert.NoError(t, err)
+	assert.Equal(t, day(-14), got)
+
+	got, err = ParseSince("36h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-36*time.Hour), got)
+
+	got, err = ParseSince("2025-09-15", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.September, 15, 0, 0, 0, 0, time.UTC), got)
+
+	_, err = ParseSince("last tuesday", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Snooze targets offered by the list's reschedule menu and `todo snooze`.
+const (
+	SnoozeToday     = "today"
+	SnoozeTomorrow  = "tomorrow"
+	SnoozeNextWeek  = "next-week"
+	SnoozeNextMonth = "next-month"
+)
+
+// ParseSnooze turns when into the due date a snoozed task moves to. It
+// accepts "today", "tomorrow", "next-week" (the coming Monday), "next-month"
+// (the first of next month), a day or week count from today ("3d", "2w"),
+// any time.ParseDuration value counted from now ("36h") or a calendar date
+// ("2025-10-01"). The result is a UTC midnight, as due dates are stored.
+func ParseSnooze(when string, now time.Time) (time.Time, error) {
+	when = strings.TrimSpace(when)
+	today := StartOfDay(now)
+	var day time.Time
+	switch when {
+	case SnoozeToday:
+		day = today
+	case SnoozeTomorrow:
+		day = today.AddDate(0, 0, 1)
+	case SnoozeNextWeek:
+		day = NextMonday(today)
+	case SnoozeNextMonth:
+		day = time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location())
+	default:
+		if date, err := time.Parse(time.DateOnly, when); err == nil {
+			return date, nil
+		}
+		if n, unit, ok := splitCount(when); ok && (unit == "d" || unit == "w") {
+			if unit == "w" {
+				n *= 7
+			}
+			day = today.AddDate(0, 0, n)
+			break
+		}
+		d, err := time.ParseDuration(when)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("invalid snooze %q: use today, tomorrow, next-week, next-month, e.g. 3d, 2w, 36h or YYYY-MM-DD", when)
+		}
+		day = now.Add(d)
+	}
+	return CalendarDay(day, time.UTC), nil
+}
+
+// IsOverdue reports whether task is open and was due before today.
+func IsOverdue(task Task, now time.Time) bool {
+	return TaskUrgency(task, now) == UrgencyOverdue
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSnooze(t *testing.T) {
+	for when, want := range map[string]time.Time{
+		"today":      day(0),
+		"tomorrow":   day(1),
+		"next-week":  day(5),
+		"next-month": time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC),
+		"3d":         day(3),
+		"2w":         day(14),
+		"36h":        day(1),
+		"2025-12-24": time.Date(2025, time.December, 24, 0, 0, 0, 0, time.UTC),
+	} {
+		got, err := ParseSnooze(when, now)
+		assert.NoError(t, err, when)
+		assert.Equal(t, want, got, when)
+	}
+
+	_, err := ParseSnooze("someday", now)
+	assert.Error(t, err)
+	_, err = ParseSnooze("-2h", now)
+	assert.Error(t, err, "snoozing backwards is not a snooze")
+}
+
+func TestParseSnooze_KeepsLocalCalendarDate(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	lateEvening := time.Date(2025, time.October, 1, 22, 0, 0, 0, ny)
+	got, err := ParseSnooze("tomorrow", lateEvening)
+	assert.NoError(t, err)
+	assert.Equal(t, day(1), got, "tomorrow is the local tomorrow, stored as a UTC midnight")
+}
+
+func TestIsOverdue(t *testing.T) {
+	assert.True(t, IsOverdue(Task{DueDate: day(-1)}, now))
+	assert.False(t, IsOverdue(Task{DueDate: day(-1), Complete: true}, now))
+	assert.False(t, IsOverdue(Task{DueDate: day(0)}, now))
+	assert.False(t, IsOverdue(Task{}, now))
+}
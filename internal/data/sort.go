@@ -0,0 +1,38 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SortKey string
+
+const (
+	SortByDue        SortKey = "due"
+	SortByCreated    SortKey = "created"
+	SortByPriority   SortKey = "priority"
+	SortByTitle      SortKey = "title"
+	SortByCompletion SortKey = "completion"
+)
+
+var SortKeys = []SortKey{SortByDue, SortByCreated, SortByPriority, SortByTitle, SortByCompletion}
+
+func ParseSortKey(s string) (SortKey, error) {
+	if s == "" {
+		return SortByDue, nil
+	}
+	for _, k := range SortKeys {
+		if strings.EqualFold(s, string(k)) {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort key %q (want one of %s)", s, joinKeys(SortKeys))
+}
+
+func joinKeys[T ~string](keys []T) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = string(k)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Status is a step of the workflow tasks move through, such as "todo" or
+// "in-progress". Tasks in a terminal status are complete.
+type Status struct {
+	Name     string
+	Terminal bool
+}
+
+// Statuses is a workflow in board order. A valid one has at least one open
+// and one terminal status.
+type Statuses []Status
+
+var statusName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Validate checks names are lowercase words joined by dashes, unique, and
+// that tasks can be both open and complete.
+func (s Statuses) Validate() error {
+	seen := map[string]bool{}
+	var open, terminal bool
+	for _, status := range s {
+		if !statusName.MatchString(status.Name) {
+			return fmt.Errorf("status %q must be lowercase letters, digits and dashes", status.Name)
+		}
+		if seen[status.Name] {
+			return fmt.Errorf("status %q is listed twice", status.Name)
+		}
+		seen[status.Name] = true
+		open = open || !status.Terminal
+		terminal = terminal || status.Terminal
+	}
+	if !open || !terminal {
+		return fmt.Errorf("statuses need at least one open and one terminal status")
+	}
+	return nil
+}
+
+// Find returns the status called name.
+func (s Statuses) Find(name string) (Status, bool) {
+	for _, status := range s {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return Status{}, false
+}
+
+// Index returns the board position of the status called name, or -1.
+func (s Statuses) Index(name string) int {
+	for i, status := range s {
+		if status.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// first returns the first terminal status when terminal is set, else the
+// first open one.
+func (s Statuses) first(terminal bool) string {
+	for _, status := range s {
+		if status.Terminal == terminal {
+			return status.Name
+		}
+	}
+	return ""
+}
+
+// Reconcile makes task's Status and Complete agree before it is stored over
+// old, the stored task (zero for a new one). An empty Status keeps old's. A
+// changed Status sets Complete from whether it is terminal; otherwise a
+// changed Complete moves the task to the first terminal or open status.
+func (s Statuses) Reconcile(old Task, task Task) (Task, error) {
+	if task.Status == "" {
+		task.Status = old.Status
+	}
+	if task.Status != "" && task.Status != old.Status {
+		status, ok := s.Find(task.Status)
+		if !ok {
+			return task, fmt.Errorf("unknown status %q", task.Status)
+		}
+		task.Complete = status.Terminal
+		return task, nil
+	}
+	if status, ok := s.Find(task.Status); !ok || status.Terminal != task.Complete {
+		task.Status = s.first(task.Complete)
+	}
+	return task, nil
+}
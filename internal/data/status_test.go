@@ -0,0 +1,48 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var workflow = Statuses{{Name: "todo"}, {Name: "doing"}, {Name: "done", Terminal: true}, {Name: "dropped", Terminal: true}}
+
+func TestStatuses_Validate(t *testing.T) {
+	assert.NoError(t, workflow.Validate())
+	assert.Error(t, Statuses{{Name: "todo"}}.Validate(), "no terminal status")
+	assert.Error(t, Statuses{{Name: "done", Terminal: true}}.Validate(), "no open status")
+	assert.Error(t, Statuses{{Name: "todo"}, {Name: "todo", Terminal: true}}.Validate(), "duplicate")
+	assert.Error(t, Statuses{{Name: "In Progress"}, {Name: "done", Terminal: true}}.Validate(), "bad name")
+}
+
+func TestStatuses_Reconcile(t *testing.T) {
+	old := Task{Status: "todo"}
+
+	task, err := workflow.Reconcile(old, Task{Status: "dropped"})
+	assert.NoError(t, err)
+	assert.True(t, task.Complete, "a terminal status completes the task")
+
+	task, err = workflow.Reconcile(Task{Status: "done", Complete: true}, Task{Status: "doing", Complete: true})
+	assert.NoError(t, err)
+	assert.False(t, task.Complete, "an open status reopens the task")
+
+	task, err = workflow.Reconcile(old, Task{Status: "todo", Complete: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "done", task.Status, "completing moves to the first terminal status")
+
+	task, err = workflow.Reconcile(Task{Status: "dropped", Complete: true}, Task{Status: "dropped"})
+	assert.NoError(t, err)
+	assert.Equal(t, "todo", task.Status, "reopening moves to the first open status")
+
+	task, err = workflow.Reconcile(Task{}, Task{Title: "new"})
+	assert.NoError(t, err)
+	assert.Equal(t, "todo", task.Status, "new tasks start in the first open status")
+
+	task, err = workflow.Reconcile(old, Task{})
+	assert.NoError(t, err)
+	assert.Equal(t, "todo", task.Status, "an empty status keeps the stored one")
+
+	_, err = workflow.Reconcile(old, Task{Status: "someday"})
+	assert.Error(t, err)
+}
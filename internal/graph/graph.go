@@ -0,0 +1,74 @@
+// Package graph draws tasks and the links between them, as printed by
+// `todo graph`, in Graphviz's DOT language.
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+// Linked returns the tasks that block or wait on another, keeping their
+// order.
+func Linked(tasks []data.Task, deps data.Dependencies) []data.Task {
+	linked := map[int]bool{}
+	for _, dep := range deps {
+		linked[dep.Blocker] = true
+		linked[dep.Blocked] = true
+	}
+	var kept []data.Task
+	for _, task := range tasks {
+		if linked[task.Id] {
+			kept = append(kept, task)
+		}
+	}
+	return kept
+}
+
+// Write prints tasks as boxes and an arrow from each blocker to the task it
+// blocks. Links to tasks that are not given are left out. Completed tasks
+// are greyed out and open tasks still waiting on others are outlined in
+// red.
+func Write(w io.Writer, tasks []data.Task, deps data.Dependencies) error {
+	shown := map[int]bool{}
+	for _, task := range tasks {
+		shown[task.Id] = true
+	}
+
+	b := bufio.NewWriter(w)
+	_, _ = fmt.Fprintln(b, "digraph todo {")
+	_, _ = fmt.Fprintln(b, "\trankdir=LR;")
+	_, _ = fmt.Fprintln(b, "\tnode [shape=box, style=rounded];")
+	for _, task := range tasks {
+		attrs := fmt.Sprintf("label=%s", quote(fmt.Sprintf("%d %s", task.Id, task.Title)))
+		switch {
+		case task.Complete:
+			attrs += `, style="rounded,filled", fillcolor=lightgrey, fontcolor=grey40`
+		case len(deps.BlockedBy(task.Id)) > 0:
+			attrs += ", color=red"
+		}
+		_, _ = fmt.Fprintf(b, "\t%d [%s];\n", task.Id, attrs)
+	}
+	for _, dep := range deps {
+		if !shown[dep.Blocker] || !shown[dep.Blocked] {
+			continue
+		}
+		line := fmt.Sprintf("\t%d -> %d", dep.Blocker, dep.Blocked)
+		if dep.BlockerComplete {
+			line += " [style=dashed]"
+		}
+		_, _ = fmt.Fprintln(b, line+";")
+	}
+	_, _ = fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote makes s a DOT string.
+func quote(s string) string {
+	return `"` + escaper.Replace(s) + `"`
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func fixtureTasks() []data.Task {
+	return []data.Task{
+		{Id: 1, Title: "Design schema", Complete: true},
+		{Id: 2, Title: `Write "migrate" script`},
+		{Id: 3, Title: "Ship release"},
+		{Id: 4, Title: "Plan holiday"},
+	}
+}
+
+func fixtureDeps() data.Dependencies {
+	return data.Dependencies{
+		{Blocker: 1, Blocked: 2, BlockerComplete: true},
+		{Blocker: 2, Blocked: 3},
+		{Blocker: 1, Blocked: 3, BlockerComplete: true},
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWrite_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), fixtureDeps()))
+	golden(t, "graph.dot", buf.Bytes())
+}
+
+func TestWrite_LeavesOutLinksToHiddenTasks(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks()[1:], fixtureDeps()))
+	assert.NotContains(t, buf.String(), "1 ->")
+	assert.Contains(t, buf.String(), "2 -> 3;")
+}
+
+func TestLinked(t *testing.T) {
+	var ids []int
+	for _, task := range Linked(fixtureTasks(), fixtureDeps()) {
+		ids = append(ids, task.Id)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+}
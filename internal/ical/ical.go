@@ -0,0 +1,341 @@
+// Package ical reads and writes tasks as RFC 5545 VTODO components.
+//
+// Exported tasks get a stable UID derived from their Id (task-3@go-todo-cli)
+// unless they were imported with a UID of their own, which is kept. On
+// import a derived UID maps back to the task Id and any other UID is kept on
+// the task so re-imports update it. Tasks have no recurrence, so RRULE is
+// never written and is ignored on import.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+const (
+	prodId         = "-//ake3mio//go-todo-cli//EN"
+	uidDomain      = "go-todo-cli"
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	projectProp    = "X-GO-TODO-PROJECT"
+	maxLineOctets  = 75
+)
+
+var derivedUID = regexp.MustCompile(`^task-(\d+)@` + regexp.QuoteMeta(uidDomain) + `$`)
+
+// UID returns the iCalendar UID a task is exported with.
+func UID(task data.Task) string {
+	if task.UID != "" {
+		return task.UID
+	}
+	return fmt.Sprintf("task-%d@%s", task.Id, uidDomain)
+}
+
+// Write writes a VCALENDAR containing one VTODO per task. now is used as the
+// DTSTAMP of every component.
+func Write(w io.Writer, tasks []data.Task, now time.Time) error {
+	lw := &lineWriter{w: w}
+	lw.prop("BEGIN", "VCALENDAR")
+	lw.prop("VERSION", "2.0")
+	lw.prop("PRODID", prodId)
+	for _, task := range tasks {
+		writeTodo(lw, task, now)
+	}
+	lw.prop("END", "VCALENDAR")
+	return lw.err
+}
+
+func writeTodo(lw *lineWriter, task data.Task, now time.Time) {
+	lw.prop("BEGIN", "VTODO")
+	lw.prop("UID", UID(task))
+	lw.prop("DTSTAMP", now.UTC().Format(dateTimeLayout))
+	if !task.CreatedAt.IsZero() {
+		lw.prop("CREATED", task.CreatedAt.UTC().Format(dateTimeLayout))
+	}
+	if !task.UpdatedAt.IsZero() {
+		lw.prop("LAST-MODIFIED", task.UpdatedAt.UTC().Format(dateTimeLayout))
+	}
+	lw.prop("SUMMARY", escape(task.Title))
+	if task.Notes != "" {
+		lw.prop("DESCRIPTION", escape(task.Notes))
+	}
+	if !task.DueDate.IsZero() {
+		lw.prop("DUE;VALUE=DATE", task.DueDate.Format(dateLayout))
+	}
+	if task.Complete {
+		lw.prop("STATUS", "COMPLETED")
+		if !task.CompletedAt.IsZero() {
+			lw.prop("COMPLETED", task.CompletedAt.UTC().Format(dateTimeLayout))
+		}
+	} else {
+		lw.prop("STATUS", "NEEDS-ACTION")
+	}
+	if p := priorityNumber(task.Priority); p > 0 {
+		lw.prop("PRIORITY", strconv.Itoa(p))
+	}
+	if len(task.Tags) > 0 {
+		escaped := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			escaped[i] = escape(tag)
+		}
+		lw.prop("CATEGORIES", strings.Join(escaped, ","))
+	}
+	if task.Project != "" {
+		lw.prop(projectProp, escape(task.Project))
+	}
+	lw.prop("END", "VTODO")
+}
+
+// priorityNumber maps A-H onto iCalendar priorities 1-8 and everything lower
+// onto 9, the lowest.
+func priorityNumber(priority string) int {
+	if priority == "" {
+		return 0
+	}
+	n := int(priority[0]-'A') + 1
+	if n > 9 {
+		n = 9
+	}
+	return n
+}
+
+func priorityLetter(n int) string {
+	if n < 1 || n > 9 {
+		return ""
+	}
+	return string(rune('A' + n - 1))
+}
+
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+// prop writes a content line, folded at 75 octets without splitting UTF-8
+// sequences, terminated by CRLF.
+func (l *lineWriter) prop(name string, value string) {
+	if l.err != nil {
+		return
+	}
+	line := name + ":" + value
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	_, l.err = io.WriteString(l.w, b.String())
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`).Replace(s)
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads every VTODO in r. Other components are skipped.
+func Parse(r io.Reader) ([]data.Task, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []data.Task
+	var todo []property
+	inTodo := false
+	for i, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VTODO":
+			inTodo, todo = true, nil
+		case prop.name == "END" && prop.value == "VTODO":
+			task, err := todoTask(todo)
+			if err != nil {
+				return nil, fmt.Errorf("VTODO ending on line %d: %w", i+1, err)
+			}
+			tasks = append(tasks, task)
+			inTodo = false
+		case inTodo:
+			todo = append(todo, prop)
+		}
+	}
+	return tasks, nil
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits NAME;PARAM=VALUE:value. Quoted parameter values may
+// contain ':' and ';'.
+func parseProperty(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return prop, fmt.Errorf("malformed content line %q", line)
+	}
+	head := strings.Split(line[:colon], ";")
+	prop.name = strings.ToUpper(head[0])
+	for _, param := range head[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	prop.value = line[colon+1:]
+	return prop, nil
+}
+
+func todoTask(props []property) (data.Task, error) {
+	var task data.Task
+	for _, prop := range props {
+		var err error
+		switch prop.name {
+		case "UID":
+			if m := derivedUID.FindStringSubmatch(prop.value); m != nil {
+				task.Id, _ = strconv.Atoi(m[1])
+			} else {
+				task.UID = prop.value
+			}
+		case "SUMMARY":
+			task.Title = unescape(prop.value)
+		case "DESCRIPTION":
+			task.Notes = unescape(prop.value)
+		case "DUE":
+			task.DueDate, err = parseDue(prop)
+		case "STATUS":
+			task.Complete = strings.EqualFold(prop.value, "COMPLETED")
+		case "COMPLETED":
+			task.CompletedAt, err = parseDateTime(prop)
+		case "CREATED":
+			task.CreatedAt, err = parseDateTime(prop)
+		case "PRIORITY":
+			var n int
+			if n, err = strconv.Atoi(prop.value); err == nil {
+				task.Priority = priorityLetter(n)
+			}
+		case "CATEGORIES":
+			for _, tag := range splitEscaped(prop.value) {
+				if tag != "" {
+					task.Tags = append(task.Tags, unescape(tag))
+				}
+			}
+		case projectProp:
+			task.Project = unescape(prop.value)
+		}
+		if err != nil {
+			return task, fmt.Errorf("%s: %w", prop.name, err)
+		}
+	}
+	if task.Title == "" {
+		return task, fmt.Errorf("missing SUMMARY")
+	}
+	if !task.CompletedAt.IsZero() {
+		task.Complete = true
+	}
+	return task, nil
+}
+
+// parseDue keeps only the calendar date of DUE, whether it is a DATE or a
+// DATE-TIME, to match how due dates are stored.
+func parseDue(prop property) (time.Time, error) {
+	value := prop.value
+	if len(value) < len(dateLayout) {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	if prop.params["VALUE"] != "DATE" && len(value) > len(dateLayout) {
+		t, err := parseDateTime(prop)
+		if err != nil {
+			return t, err
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse(dateLayout, value[:len(dateLayout)])
+}
+
+// parseDateTime accepts UTC (trailing Z), TZID-qualified and floating
+// DATE-TIME values, and plain DATEs as local midnight.
+func parseDateTime(prop property) (time.Time, error) {
+	value := prop.value
+	loc := time.Local
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(dateTimeLayout, value)
+	case len(value) == len(dateLayout):
+		return time.ParseInLocation(dateLayout, value, loc)
+	default:
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+}
+
+func splitEscaped(s string) []string {
+	var parts []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(parts, b.String())
+}
@@ -0,0 +1,125 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var stamp = time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+
+func fixtureTasks() []data.Task {
+	return []data.Task{
+		{
+			Id:        1,
+			Title:     "Write report; draft, then final",
+			DueDate:   time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC),
+			Priority:  "A",
+			Project:   "work",
+			Tags:      []string{"docs", "q4"},
+			Notes:     "Use the Q3 template.\nSend to the team when done.",
+			CreatedAt: time.Date(2025, time.September, 28, 8, 30, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2025, time.September, 29, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			Id:          2,
+			Title:       "A very long task title that needs folding because it runs past seventy-five octets",
+			Complete:    true,
+			DueDate:     time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC),
+			CreatedAt:   time.Date(2025, time.September, 20, 8, 0, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2025, time.September, 30, 12, 0, 0, 0, time.UTC),
+			CompletedAt: time.Date(2025, time.September, 30, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			Id:    3,
+			Title: "Imported from a calendar",
+			UID:   "040000008200E00074C5B7101A82E008@example.com",
+		},
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWrite_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), stamp))
+	golden(t, "export.ics", buf.Bytes())
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "content lines are folded at 75 octets")
+	}
+}
+
+func TestParse_RoundTripsExport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), stamp))
+
+	got, err := Parse(&buf)
+	require.NoError(t, err)
+
+	want := fixtureTasks()
+	for i := range want {
+		// LAST-MODIFIED is owned by the repository and not imported.
+		want[i].UpdatedAt = time.Time{}
+		assert.True(t, want[i].CreatedAt.Equal(got[i].CreatedAt))
+		assert.True(t, want[i].CompletedAt.Equal(got[i].CompletedAt))
+		want[i].CreatedAt, got[i].CreatedAt = time.Time{}, time.Time{}
+		want[i].CompletedAt, got[i].CompletedAt = time.Time{}, time.Time{}
+	}
+	// Tasks with a foreign UID are matched by it, not by Id.
+	want[2].Id = 0
+	assert.Equal(t, want, got)
+}
+
+func TestParse_ThirdPartyCalendar(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "import.ics"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, err := Parse(f)
+	require.NoError(t, err)
+	if assert.Len(t, got, 2, "VEVENTs are skipped") {
+		assert.Equal(t, "19970901T130000Z-123404@host.com", got[0].UID)
+		assert.Equal(t, 0, got[0].Id)
+		assert.Equal(t, "Submit quarterly report, with annex", got[0].Title)
+		assert.Equal(t, time.Date(2025, time.October, 7, 0, 0, 0, 0, time.UTC), got[0].DueDate, "DATE-TIME due keeps its calendar date")
+		assert.Equal(t, "A", got[0].Priority)
+		assert.Equal(t, []string{"FAMILY", "FINANCE"}, got[0].Tags)
+
+		assert.Equal(t, 12, got[1].Id, "derived UIDs map back to task ids")
+		assert.True(t, got[1].Complete)
+		assert.True(t, time.Date(2025, time.October, 2, 10, 0, 0, 0, time.UTC).Equal(got[1].CompletedAt))
+	}
+}
+
+func TestParse_RequiresSummary(t *testing.T) {
+	_, err := Parse(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:x\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"))
+	assert.ErrorContains(t, err, "SUMMARY")
+}
+
+func TestPriorityMapping(t *testing.T) {
+	assert.Equal(t, 0, priorityNumber(""))
+	assert.Equal(t, 1, priorityNumber("A"))
+	assert.Equal(t, 9, priorityNumber("Z"))
+	assert.Equal(t, "A", priorityLetter(1))
+	assert.Equal(t, "", priorityLetter(0))
+}
@@ -0,0 +1,139 @@
+// Package interchange reconciles tasks read from an external format with
+// the tasks already stored, so imports can be previewed before they are
+// applied.
+package interchange
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+)
+
+type Action string
+
+const (
+	Create    Action = "create"
+	Update    Action = "update"
+	Unchanged Action = "unchanged"
+)
+
+// Step is what an import would do with one incoming task.
+type Step struct {
+	Action  Action
+	Task    data.Task
+	Changes []data.Change
+}
+
+// Plan matches incoming tasks to existing ones by Id, then by UID. Matched
+// tasks become updates carrying the field-level diff, or Unchanged when
+// nothing differs; anything else is created. Timestamps on matched tasks are
+// left to the repository. A data.PendingParent ParentId naming an earlier
+// incoming task is resolved to that task's Id once it is matched, and
+// otherwise left pending on the plan step that creates the parent.
+func Plan(existing []data.Task, incoming []data.Task) []Step {
+	byId := make(map[int]data.Task, len(existing))
+	byUID := make(map[string]data.Task, len(existing))
+	for _, task := range existing {
+		byId[task.Id] = task
+		if task.UID != "" {
+			byUID[task.UID] = task
+		}
+	}
+
+	steps := make([]Step, 0, len(incoming))
+	for i, task := range incoming {
+		if index, ok := data.PendingIndex(task.ParentId); ok {
+			switch {
+			case index >= i:
+				task.ParentId = 0
+			case steps[index].Task.Id != 0:
+				task.ParentId = steps[index].Task.Id
+			}
+		}
+		current, ok := byId[task.Id]
+		if !ok && task.UID != "" {
+			current, ok = byUID[task.UID]
+		}
+		if !ok {
+			task.Id = 0
+			steps = append(steps, Step{Action: Create, Task: task, Changes: data.CreatedChanges(task)})
+			continue
+		}
+		task.Id = current.Id
+		if task.UID == "" {
+			task.UID = current.UID
+		}
+		task.CreatedAt = current.CreatedAt
+		task.UpdatedAt = current.UpdatedAt
+		task.CompletedAt = current.CompletedAt
+		task.Version = current.Version
+		// No format carries protection or status, so imports leave them as
+		// they are; the repository moves the status if Complete changed.
+		task.Protected = current.Protected
+		task.Status = current.Status
+		changes := data.DiffTasks(current, task)
+		action := Update
+		if len(changes) == 0 {
+			action = Unchanged
+		}
+		steps = append(steps, Step{Action: action, Task: task, Changes: changes})
+	}
+	return steps
+}
+
+// Apply performs every create and update in plan in one transaction. It
+// fails with a *persistence.ConflictError, changing nothing, when a task
+// has been edited since the plan was made.
+func Apply(repository persistence.TodoRepository, plan []Step) error {
+	var tasks []data.Task
+	batchIndex := make(map[int]int, len(plan))
+	for i, step := range plan {
+		if step.Action == Unchanged {
+			continue
+		}
+		task := step.Task
+		if index, ok := data.PendingIndex(task.ParentId); ok {
+			task.ParentId = data.PendingParent(batchIndex[index])
+		}
+		batchIndex[i] = len(tasks)
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	_, err := repository.UpsertTasks(tasks)
+	return err
+}
+
+// Report writes a human-readable summary of plan, one line per create or
+// update followed by its changes, and a closing tally worded for a dry run
+// or a completed import.
+func Report(w io.Writer, plan []Step, dryRun bool) error {
+	counts := map[Action]int{}
+	for _, step := range plan {
+		counts[step.Action]++
+		if step.Action == Unchanged {
+			continue
+		}
+		name := "new task"
+		if step.Action == Update {
+			name = fmt.Sprintf("task %d", step.Task.Id)
+		}
+		if _, err := fmt.Fprintf(w, "%s %s: %s\n", step.Action, name, step.Task.Title); err != nil {
+			return err
+		}
+		for _, change := range step.Changes {
+			if _, err := fmt.Fprintf(w, "    %s\n", change); err != nil {
+				return err
+			}
+		}
+	}
+	format := "%d created, %d updated, %d unchanged\n"
+	if dryRun {
+		format = "dry run: %d to create, %d to update, %d unchanged\n"
+	}
+	_, err := fmt.Fprintf(w, format, counts[Create], counts[Update], counts[Unchanged])
+	return err
+}
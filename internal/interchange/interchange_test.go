@@ -0,0 +1,121 @@
+package interchange
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRepo struct {
+	persistence.TodoRepository
+	upserted [][]data.Task
+}
+
+func (r *fakeRepo) UpsertTasks(tasks []data.Task) ([]int, error) {
+	r.upserted = append(r.upserted, tasks)
+	return make([]int, len(tasks)), nil
+}
+
+var due = time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+func existing() []data.Task {
+	return []data.Task{
+		{Id: 1, Title: "Same", DueDate: due, CreatedAt: due},
+		{Id: 2, Title: "Old title", DueDate: due, CreatedAt: due},
+	}
+}
+
+func TestPlan_MatchesById(t *testing.T) {
+	plan := Plan(existing(), []data.Task{
+		{Id: 1, Title: "Same", DueDate: due},
+		{Id: 2, Title: "New title", DueDate: due},
+		{Id: 9, Title: "Unknown id"},
+		{Title: "Brand new"},
+	})
+
+	if assert.Len(t, plan, 4) {
+		assert.Equal(t, Unchanged, plan[0].Action)
+		assert.Equal(t, Update, plan[1].Action)
+		assert.Equal(t, []data.Change{{Field: "title", Old: "Old title", New: "New title"}}, plan[1].Changes)
+		assert.Equal(t, due, plan[1].Task.CreatedAt, "repository timestamps are kept on update")
+		assert.Equal(t, Create, plan[2].Action)
+		assert.Equal(t, 0, plan[2].Task.Id, "ids unknown to this database are not reused")
+		assert.Equal(t, Create, plan[3].Action)
+	}
+}
+
+func TestPlan_MatchesByUIDWhenIdIsUnknown(t *testing.T) {
+	current := append(existing(), data.Task{Id: 3, Title: "From calendar", UID: "abc@example.com"})
+	plan := Plan(current, []data.Task{
+		{Title: "From calendar, edited", UID: "abc@example.com"},
+		{Title: "Another", UID: "new@example.com"},
+	})
+
+	if assert.Len(t, plan, 2) {
+		assert.Equal(t, Update, plan[0].Action)
+		assert.Equal(t, 3, plan[0].Task.Id)
+		assert.Equal(t, Create, plan[1].Action)
+		assert.Equal(t, "new@example.com", plan[1].Task.UID)
+	}
+}
+
+func TestApply_UpsertsOnlyChangesInOneCall(t *testing.T) {
+	repo := &fakeRepo{}
+	plan := Plan(existing(), []data.Task{
+		{Id: 1, Title: "Same", DueDate: due},
+		{Title: "Brand new"},
+	})
+
+	assert.NoError(t, Apply(repo, plan))
+	if assert.Len(t, repo.upserted, 1) {
+		assert.Len(t, repo.upserted[0], 1)
+		assert.Equal(t, "Brand new", repo.upserted[0][0].Title)
+	}
+
+	repo = &fakeRepo{}
+	assert.NoError(t, Apply(repo, Plan(existing(), existing())))
+	assert.Empty(t, repo.upserted, "nothing to write")
+}
+
+func TestPlanAndApply_ResolveSubtaskParents(t *testing.T) {
+	repo := &fakeRepo{}
+	plan := Plan(existing(), []data.Task{
+		{Id: 1, Title: "Same", DueDate: due},
+		{Title: "Under existing", ParentId: data.PendingParent(0)},
+		{Title: "New parent"},
+		{Title: "Under new", ParentId: data.PendingParent(2)},
+	})
+
+	assert.Equal(t, 1, plan[1].Task.ParentId, "matched parents resolve to their id")
+	assert.Equal(t, data.PendingParent(2), plan[3].Task.ParentId, "new parents stay pending")
+
+	assert.NoError(t, Apply(repo, plan))
+	if assert.Len(t, repo.upserted, 1) && assert.Len(t, repo.upserted[0], 3) {
+		assert.Equal(t, data.PendingParent(1), repo.upserted[0][2].ParentId, "pending parents point into the batch")
+	}
+}
+
+func TestReport(t *testing.T) {
+	plan := Plan(existing(), []data.Task{
+		{Id: 1, Title: "Same", DueDate: due},
+		{Id: 2, Title: "New title", DueDate: due},
+		{Title: "Brand new"},
+	})
+
+	var buf bytes.Buffer
+	assert.NoError(t, Report(&buf, plan, true))
+	assert.Equal(t, `update task 2: New title
+    title: "Old title" → "New title"
+create new task: Brand new
+    title: "Brand new"
+dry run: 1 to create, 1 to update, 1 unchanged
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Report(&buf, plan, false))
+	assert.Contains(t, buf.String(), "1 created, 1 updated, 1 unchanged")
+}
@@ -0,0 +1,178 @@
+// Package markdown reads and writes tasks as Markdown checklists, the
+// "- [ ]" task lists understood by GitHub and most note-taking apps.
+//
+// Exported tasks carry their due date as a trailing "_(due 2025-10-05)_"
+// annotation and their id in an HTML comment, which renderers hide, so an
+// edited checklist can be imported back over the same tasks. Subtasks are
+// nested under their parent.
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+const indentWidth = 2
+
+// Write renders tasks as a checklist under a "##" heading per group, keeping
+// the order tasks are given in. Subtasks follow their parent, indented,
+// whichever group they would fall in themselves. With data.GroupByNone the
+// headings are left out.
+func Write(w io.Writer, tasks []data.Task, by data.GroupBy, now time.Time) error {
+	present := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		present[task.Id] = true
+	}
+	var top []data.Task
+	subtasks := map[int][]data.Task{}
+	for _, task := range tasks {
+		if task.ParentId != 0 && present[task.ParentId] && task.ParentId != task.Id {
+			subtasks[task.ParentId] = append(subtasks[task.ParentId], task)
+			continue
+		}
+		top = append(top, task)
+	}
+
+	bw := bufio.NewWriter(w)
+	headings := by != data.GroupByNone && by != ""
+	first := true
+	for _, group := range data.GroupTasks(top, by, now) {
+		if len(group.Tasks) == 0 {
+			continue
+		}
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+		if headings {
+			fmt.Fprintf(bw, "## %s\n\n", group.Name)
+		}
+		written := map[int]bool{}
+		for _, task := range group.Tasks {
+			writeItem(bw, task, 0, subtasks, written)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeItem writes task and, beneath it, its subtasks. written guards
+// against parent cycles.
+func writeItem(w *bufio.Writer, task data.Task, depth int, subtasks map[int][]data.Task, written map[int]bool) {
+	if written[task.Id] {
+		return
+	}
+	written[task.Id] = true
+	w.WriteString(Format(task, depth))
+	w.WriteString("\n")
+	for _, sub := range subtasks[task.Id] {
+		writeItem(w, sub, depth+1, subtasks, written)
+	}
+}
+
+// Format renders one task as a checklist item indented to depth.
+func Format(task data.Task, depth int) string {
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", depth*indentWidth))
+	if task.Complete {
+		b.WriteString("- [x] ")
+	} else {
+		b.WriteString("- [ ] ")
+	}
+	b.WriteString(task.Title)
+	if !task.DueDate.IsZero() {
+		fmt.Fprintf(&b, " _(due %s)_", task.DueDate.Format(time.DateOnly))
+	}
+	if task.Id != 0 {
+		fmt.Fprintf(&b, " <!-- id:%d -->", task.Id)
+	}
+	return b.String()
+}
+
+var (
+	itemPattern = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d+[.)])[ \t]+\[([ xX])\][ \t]+(.*)$`)
+	idPattern   = regexp.MustCompile(`[ \t]*<!--[ \t]*id:(\d+)[ \t]*-->[ \t]*$`)
+	duePattern  = regexp.MustCompile(`[ \t]*_?\(due (\d{4}-\d{2}-\d{2})\)_?[ \t]*$`)
+)
+
+// Parse reads every checklist item in r. Other lines, including headings and
+// plain list items, are skipped. An item indented beneath another becomes its
+// subtask, with a data.PendingParent ParentId naming the parent's position in
+// the result.
+func Parse(r io.Reader) ([]data.Task, error) {
+	type open struct {
+		indent int
+		index  int
+	}
+	var tasks []data.Task
+	var stack []open
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		m := itemPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		task, err := parseItem(m[2], m[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		indent := indentation(m[1])
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			task.ParentId = data.PendingParent(stack[len(stack)-1].index)
+		}
+		stack = append(stack, open{indent: indent, index: len(tasks)})
+		tasks = append(tasks, task)
+	}
+	return tasks, scanner.Err()
+}
+
+func parseItem(mark string, text string) (data.Task, error) {
+	task := data.Task{Complete: mark != " "}
+	if m := idPattern.FindStringSubmatch(text); m != nil {
+		id, err := strconv.Atoi(m[1])
+		if err != nil {
+			return task, fmt.Errorf("invalid id %q", m[1])
+		}
+		task.Id = id
+		text = text[:len(text)-len(m[0])]
+	}
+	if m := duePattern.FindStringSubmatch(text); m != nil {
+		due, err := time.Parse(time.DateOnly, m[1])
+		if err != nil {
+			return task, fmt.Errorf("invalid due date %q", m[1])
+		}
+		task.DueDate = due
+		text = text[:len(text)-len(m[0])]
+	}
+	task.Title = strings.TrimSpace(text)
+	if task.Title == "" {
+		return task, fmt.Errorf("checklist item has no title")
+	}
+	return task, nil
+}
+
+// indentation measures leading whitespace, counting a tab as four spaces.
+func indentation(s string) int {
+	n := 0
+	for _, r := range s {
+		if r == '\t' {
+			n += 4
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var now = time.Date(2025, time.October, 1, 10, 30, 0, 0, time.UTC)
+
+func day(offset int) time.Time {
+	return time.Date(2025, time.October, 1+offset, 0, 0, 0, 0, time.UTC)
+}
+
+func fixtureTasks() []data.Task {
+	return []data.Task{
+		{Id: 1, Title: "Pay rent", DueDate: day(-1)},
+		{Id: 2, Title: "Write report", DueDate: day(0)},
+		{Id: 3, Title: "Draft outline", Complete: true, ParentId: 2},
+		{Id: 4, Title: "Collect figures", DueDate: day(2), ParentId: 2},
+		{Id: 5, Title: "Ask finance", ParentId: 4},
+		{Id: 6, Title: "Plan holiday"},
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWrite_GroupedGolden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), data.GroupByDue, now))
+	golden(t, "export.md", buf.Bytes())
+}
+
+func TestWrite_Ungrouped(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, []data.Task{{Title: "Loose"}, {Id: 7, Title: "Done", Complete: true}}, data.GroupByNone, now))
+	assert.Equal(t, "- [ ] Loose\n- [x] Done <!-- id:7 -->\n", buf.String())
+}
+
+func TestParse_RoundTripsExport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), data.GroupByDue, now))
+
+	got, err := Parse(&buf)
+	require.NoError(t, err)
+
+	// Subtasks come back as references to their parent's position.
+	want := fixtureTasks()
+	want[2].ParentId = data.PendingParent(1)
+	want[3].ParentId = data.PendingParent(1)
+	want[4].ParentId = data.PendingParent(3)
+	assert.Equal(t, want, got)
+}
+
+func TestParse_HandWrittenChecklist(t *testing.T) {
+	got, err := Parse(strings.NewReader(`# Moving house
+
+Some notes that are not tasks.
+
+* [X] Book van (due 2025-10-04)
+	* [ ] Confirm time
+	- plain bullet, skipped
+1. [ ] Pack kitchen
+   2. [ ] Buy boxes
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []data.Task{
+		{Title: "Book van", Complete: true, DueDate: day(3)},
+		{Title: "Confirm time", ParentId: data.PendingParent(0)},
+		{Title: "Pack kitchen"},
+		{Title: "Buy boxes", ParentId: data.PendingParent(2)},
+	}, got)
+}
+
+func TestParse_ReportsLineNumbers(t *testing.T) {
+	_, err := Parse(strings.NewReader("- [ ] Fine\n- [ ] Bad _(due 2025-13-01)_\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,264 @@
+package persistence
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ncruces/go-sqlite3/driver"
+)
+
+// keepBackups is how many automatic backups are kept beside the database.
+const keepBackups = 5
+
+const backupTimeLayout = "20060102T150405Z"
+
+// BackupDir is where automatic backups of the database are kept.
+func BackupDir() string {
+	return backupDir(dbFilePath())
+}
+
+func backupDir(path string) string {
+	return path + ".backups"
+}
+
+// Backup writes a consistent, compacted copy of the database to path with
+// VACUUM INTO. It is safe to run while other processes use the database.
+func Backup(path string) error {
+	if fileExists(path) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	db, err := openExisting()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return vacuumInto(context.TODO(), db, path)
+}
+
+// Check runs SQLite's integrity check and returns the problems it reports,
+// or nil for a healthy database.
+func Check() ([]string, error) {
+	db, err := openExisting()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return integrityCheck(context.TODO(), db)
+}
+
+// Restore replaces the database with the backup at path, then migrates it to
+// the current schema. The backup must be a healthy task database no newer
+// than this build understands. The database being replaced is first saved
+// as an automatic backup, whose path is returned, when there is one.
+func Restore(path string) (previous string, err error) {
+	ctx := context.TODO()
+	if err := validateBackup(ctx, path); err != nil {
+		return "", err
+	}
+
+	target := dbFilePath()
+	existed := fileExists(target)
+	db, err := openDB(target)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	if existed {
+		if previous, err = rotateBackup(ctx, db, target, "pre-restore", time.Now()); err != nil {
+			return "", err
+		}
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return previous, err
+	}
+	err = conn.Raw(func(driverConn any) error {
+		return driverConn.(driver.Conn).Raw().Restore("main", path)
+	})
+	if closeErr := conn.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return previous, err
+	}
+
+	if _, err = db.ExecContext(ctx, schema); err != nil {
+		return previous, err
+	}
+	return previous, migrate(ctx, db)
+}
+
+func openExisting() (*sql.DB, error) {
+	path := dbFilePath()
+	if !fileExists(path) {
+		return nil, fmt.Errorf("no database at %s", path)
+	}
+	return openDB(path)
+}
+
+// validateBackup opens path read-only and checks it holds tasks, passes the
+// integrity check and has a schema version this build can migrate from.
+func validateBackup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var tables int
+	err = db.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'tasks'`).Scan(&tables)
+	if err != nil {
+		return fmt.Errorf("%s is not a task database: %w", path, err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("%s is not a task database: no tasks table", path)
+	}
+
+	version, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	names, err := migrations()
+	if err != nil {
+		return err
+	}
+	if version > len(names) {
+		return fmt.Errorf("%s has schema version %d, newer than the %d this version of todo supports", path, version, len(names))
+	}
+
+	problems, err := integrityCheck(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s failed the integrity check: %s", path, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func integrityCheck(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	return problems, rows.Err()
+}
+
+func vacuumInto(ctx context.Context, db *sql.DB, path string) error {
+	_, err := db.ExecContext(ctx, `VACUUM INTO ?`, path)
+	return err
+}
+
+// backupBeforeMigrate saves a copy of the database when migrations are
+// about to change its schema.
+func backupBeforeMigrate(ctx context.Context, db *sql.DB, path string, now time.Time) error {
+	names, err := migrations()
+	if err != nil {
+		return err
+	}
+	version, err := schemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if version >= len(names) {
+		return nil
+	}
+	_, err = rotateBackup(ctx, db, path, fmt.Sprintf("pre-migrate-v%d", version), now)
+	return err
+}
+
+// rotateBackup writes an automatic backup of the database at path into its
+// backup directory, named by time and reason, and prunes all but the newest
+// keepBackups.
+func rotateBackup(ctx context.Context, db *sql.DB, path string, reason string, now time.Time) (string, error) {
+	backups := backupDir(path)
+	if err := os.MkdirAll(backups, 0o755); err != nil {
+		return "", err
+	}
+	stamp := now.UTC().Format(backupTimeLayout)
+	name := filepath.Join(backups, fmt.Sprintf("%s-%s.sqlite", stamp, reason))
+	for n := 2; fileExists(name); n++ {
+		name = filepath.Join(backups, fmt.Sprintf("%s-%s-%d.sqlite", stamp, reason, n))
+	}
+	if err := vacuumInto(ctx, db, name); err != nil {
+		return "", err
+	}
+	return name, pruneBackups(backups)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// backupName matches the names rotateBackup gives backups: the time, the
+// reason and, for further backups in the same second, a number.
+var backupName = regexp.MustCompile(`^(\d{8}T\d{6}Z)-[a-z0-9-]+?(?:-(\d+))?\.sqlite$`)
+
+type backupFile struct {
+	path string
+	at   time.Time
+	n    int
+}
+
+// pruneBackups removes all but the newest keepBackups automatic backups in
+// dir, leaving any other files alone.
+func pruneBackups(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		m := backupName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		at, err := time.Parse(backupTimeLayout, m[1])
+		if err != nil {
+			continue
+		}
+		n := 1
+		if m[2] != "" {
+			n, _ = strconv.Atoi(m[2])
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, entry.Name()), at: at, n: n})
+	}
+	slices.SortFunc(backups, func(a, b backupFile) int {
+		return cmp.Or(a.at.Compare(b.at), cmp.Compare(a.n, b.n))
+	})
+	for len(backups) > keepBackups {
+		if err := os.Remove(backups[0].path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Backup_And_Restore_Round_Trip(t *testing.T) {
+	repo := mustNewRepo(t)
+	_, err := (*repo).CreateTask(data.Task{Title: "Keep me"})
+	require.NoError(t, err)
+	require.NoError(t, (*repo).Close())
+
+	backup := filepath.Join(t.TempDir(), "backup.sqlite")
+	require.NoError(t, Backup(backup))
+	assert.ErrorContains(t, Backup(backup), "already exists")
+
+	repo = mustReopen(t)
+	require.NoError(t, (*repo).DeleteTaskById(1))
+	require.NoError(t, (*repo).Close())
+
+	previous, err := Restore(backup)
+	require.NoError(t, err)
+	assert.FileExists(t, previous)
+	assert.Equal(t, BackupDir(), filepath.Dir(previous))
+
+	repo = mustReopen(t)
+	tasks, err := (*repo).GetTasks()
+	require.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "Keep me", tasks[0].Title)
+	}
+	require.NoError(t, (*repo).Close())
+}
+
+func Test_Restore_Rejects_Invalid_Backups(t *testing.T) {
+	repo := mustNewRepo(t)
+	require.NoError(t, (*repo).Close())
+	dir := t.TempDir()
+
+	names, err := migrations()
+	require.NoError(t, err)
+	newer := filepath.Join(dir, "newer.sqlite")
+	writeDB(t, newer, schema, fmt.Sprintf("PRAGMA user_version = %d", len(names)+1))
+	_, err = Restore(newer)
+	assert.ErrorContains(t, err, "newer than")
+
+	other := filepath.Join(dir, "other.sqlite")
+	writeDB(t, other, "CREATE TABLE notes (body TEXT)")
+	_, err = Restore(other)
+	assert.ErrorContains(t, err, "not a task database")
+
+	garbage := filepath.Join(dir, "garbage.sqlite")
+	require.NoError(t, os.WriteFile(garbage, []byte("definitely not sqlite, but long enough to have a header..."), 0o644))
+	_, err = Restore(garbage)
+	assert.ErrorContains(t, err, "not a task database")
+
+	_, err = os.Stat(BackupDir())
+	assert.True(t, os.IsNotExist(err), "nothing is touched when validation fails")
+}
+
+func Test_Restore_Migrates_Older_Backups(t *testing.T) {
+	repo := mustNewRepo(t)
+	require.NoError(t, (*repo).Close())
+
+	old := filepath.Join(t.TempDir(), "old.sqlite")
+	writeDB(t, old, schema, `INSERT INTO tasks (title, due_date) VALUES ('From v0', 0)`)
+	_, err := Restore(old)
+	require.NoError(t, err)
+
+	repo = mustReopen(t)
+	tasks, err := (*repo).GetTasks()
+	require.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "From v0", tasks[0].Title)
+	}
+	require.NoError(t, (*repo).Close())
+}
+
+func Test_Check_Reports_Healthy_Database(t *testing.T) {
+	repo := mustNewRepo(t)
+	require.NoError(t, (*repo).Close())
+
+	problems, err := Check()
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+
+	t.Setenv("TODO_DB", filepath.Join(t.TempDir(), "missing.sqlite"))
+	_, err = Check()
+	assert.ErrorContains(t, err, "no database")
+}
+
+func Test_NewTodoRepository_Backs_Up_Before_Migrating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.sqlite")
+	t.Setenv("TODO_DB", path)
+	writeDB(t, path, schema, `INSERT INTO tasks (title, due_date) VALUES ('Old', 0)`)
+
+	repo := NewTodoRepository()
+	require.NoError(t, repo.Close())
+	backups, err := filepath.Glob(filepath.Join(BackupDir(), "*-pre-migrate-v0.sqlite"))
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	repo = NewTodoRepository()
+	require.NoError(t, repo.Close())
+	backups, err = filepath.Glob(filepath.Join(BackupDir(), "*.sqlite"))
+	require.NoError(t, err)
+	assert.Len(t, backups, 1, "an up-to-date database is not backed up again")
+}
+
+func Test_RotateBackup_Keeps_The_Newest(t *testing.T) {
+	repo := mustNewRepo(t)
+	db := (*repo).(*SqlLiteTodoRepository).db
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+
+	start := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+	var names []string
+	for i := 0; i < keepBackups+2; i++ {
+		name, err := rotateBackup(context.Background(), db, dbFilePath(), "test", start.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+		names = append(names, name)
+	}
+	again, err := rotateBackup(context.Background(), db, dbFilePath(), "test", start.Add(time.Duration(keepBackups+1)*time.Minute))
+	require.NoError(t, err)
+	assert.NotEqual(t, names[len(names)-1], again, "backups in the same second do not collide")
+
+	kept, err := filepath.Glob(filepath.Join(BackupDir(), "*.sqlite"))
+	require.NoError(t, err)
+	assert.Len(t, kept, keepBackups)
+	assert.NoFileExists(t, names[0])
+	assert.FileExists(t, again)
+
+	other := filepath.Join(BackupDir(), "copy.sqlite")
+	require.NoError(t, os.WriteFile(other, nil, 0o600))
+	for i := 0; i < keepBackups-1; i++ {
+		_, err := rotateBackup(context.Background(), db, dbFilePath(), "test", start.Add(time.Hour+time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+	assert.NoFileExists(t, names[len(names)-1])
+	assert.FileExists(t, again, "a second backup in the same second is the newer")
+	assert.FileExists(t, other, "files rotateBackup did not write are left alone")
+}
+
+func mustReopen(t *testing.T) *TodoRepository {
+	t.Helper()
+	repo := NewTodoRepository()
+	return &repo
+}
+
+// writeDB creates a standalone SQLite database at path by running statements.
+func writeDB(t *testing.T, path string, statements ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+path)
+	require.NoError(t, err)
+	defer db.Close()
+	for _, statement := range statements {
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,140 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+// lookupBatch is how many ids getTasksTx puts in one query, well under
+// SQLite's limit on bound parameters.
+const lookupBatch = 500
+
+// getTasksTx reads the stored rows for ids, a batch of ids per query. Ids
+// with no row are absent from the result.
+func getTasksTx(ctx context.Context, tx *sql.Tx, ids []int) (map[int]data.Task, error) {
+	stored := make(map[int]data.Task, len(ids))
+	for start := 0; start < len(ids); start += lookupBatch {
+		chunk := ids[start:min(start+lookupBatch, len(ids))]
+		args := make([]any, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(chunk)), ",")
+		rows, err := tx.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := scanTasks(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			stored[task.Id] = task
+		}
+	}
+	return stored, nil
+}
+
+// taskWriter updates tasks within one transaction through prepared
+// statements, so a batch parses its SQL once however many rows it writes.
+type taskWriter struct {
+	now      func() time.Time
+	tx       *sql.Tx
+	statuses data.Statuses
+	update   *sql.Stmt
+	event    *sql.Stmt
+	// completed lists the tasks write has completed, for checkBlocked.
+	completed []int
+}
+
+func (t *SqlLiteTodoRepository) prepareWriter(ctx context.Context, tx *sql.Tx) (*taskWriter, error) {
+	statuses, err := getStatusesTx(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	update, err := tx.PrepareContext(ctx, `
+UPDATE tasks SET
+	title = ?, complete = ?, status = ?, due_date = ?, priority = ?, project = ?, tags = ?, notes = ?, parent_id = ?, protected = ?,
+	updated_at = ?, version = version + 1,
+	completed_at = CASE WHEN ? THEN COALESCE(completed_at, ?) END
+WHERE id = ?`)
+	if err != nil {
+		return nil, err
+	}
+	event, err := tx.PrepareContext(ctx, insertEvent)
+	if err != nil {
+		_ = update.Close()
+		return nil, err
+	}
+	return &taskWriter{now: t.now, tx: tx, statuses: statuses, update: update, event: event}, nil
+}
+
+func (w *taskWriter) Close() error {
+	_ = w.event.Close()
+	return w.update.Close()
+}
+
+// write stores task over old, its stored row, and records the field-level
+// diff. Status and Complete are reconciled first; see
+// data.Statuses.Reconcile. Unchanged tasks are left alone, so updated_at and version only move
+// on real edits. Changing a task whose Version is behind the stored one is
+// a *ConflictError.
+func (w *taskWriter) write(ctx context.Context, old data.Task, task data.Task) error {
+	task, err := w.statuses.Reconcile(old, task)
+	if err != nil {
+		return err
+	}
+	return w.store(ctx, old, task)
+}
+
+// checkBlocked returns a *BlockedError when a task write completed still
+// waits on an open task. It runs once the whole batch is written, so a
+// blocker completed in the same batch no longer counts.
+func (w *taskWriter) checkBlocked(ctx context.Context) error {
+	for _, id := range w.completed {
+		blockers, err := openBlockersTx(ctx, w.tx, id)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > 0 {
+			return &BlockedError{Id: id, Blockers: blockers}
+		}
+	}
+	return nil
+}
+
+// store is write without reconciling, for callers that already made Status
+// and Complete agree. Like write, it records the tasks it completes for
+// checkBlocked.
+func (w *taskWriter) store(ctx context.Context, old data.Task, task data.Task) error {
+	changes := data.DiffTasks(old, task)
+	if len(changes) == 0 {
+		return nil
+	}
+	if task.Version != 0 && task.Version != old.Version {
+		return &ConflictError{Id: task.Id, Current: old}
+	}
+
+	now := w.now().UTC().Unix()
+	_, err := w.update.ExecContext(ctx,
+		task.Title, task.Complete, task.Status, task.DueDate.UTC().Unix(), task.Priority, task.Project, joinTags(task.Tags), task.Notes, task.ParentId, task.Protected,
+		now, task.Complete, now, task.Id)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodeChanges(changes)
+	if err != nil {
+		return err
+	}
+	if _, err = w.event.ExecContext(ctx, task.Id, string(data.EventUpdated), now, encoded); err != nil {
+		return err
+	}
+	if task.Complete && !old.Complete {
+		w.completed = append(w.completed, task.Id)
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package persistence
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+// The daemon owns the database and answers TodoRepository calls from other
+// processes as JSON-RPC over a Unix socket beside the database, so that
+// concurrent clients queue in one process instead of contending for SQLite
+// locks. NewTodoRepository uses it whenever it is running.
+
+const dialTimeout = 200 * time.Millisecond
+
+// SocketPath is where the daemon for the current database listens. It can be
+// overridden with TODO_SOCKET.
+func SocketPath() string {
+	if e := os.Getenv("TODO_SOCKET"); e != "" {
+		return e
+	}
+	return dbFilePath() + ".sock"
+}
+
+// DaemonStatus describes a running daemon.
+type DaemonStatus struct {
+	Pid      int
+	Database string
+	Since    time.Time
+}
+
+// Serve answers repository calls from clients accepted on listener until the
+// listener is closed. stop is called when a client asks the daemon to stop.
+func Serve(listener net.Listener, repository TodoRepository, stop func()) error {
+	status := DaemonStatus{Pid: os.Getpid(), Database: dbFilePath(), Since: time.Now()}
+	writes := new(atomic.Int64)
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Each client gets its own service, which knows its own writes.
+		server := rpc.NewServer()
+		if err := server.RegisterName("Tasks", &taskService{repository: repository, writes: writes}); err != nil {
+			_ = conn.Close()
+			return err
+		}
+		if err := server.RegisterName("Daemon", &daemonService{status: status, stop: stop}); err != nil {
+			_ = conn.Close()
+			return err
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// GetDaemonStatus asks a running daemon about itself.
+func GetDaemonStatus() (DaemonStatus, error) {
+	var status DaemonStatus
+	client, err := dialDaemon()
+	if err != nil {
+		return status, err
+	}
+	defer client.Close()
+	err = client.Call("Daemon.Status", struct{}{}, &status)
+	return status, err
+}
+
+// StopDaemon asks a running daemon to shut down.
+func StopDaemon() error {
+	client, err := dialDaemon()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Call("Daemon.Stop", struct{}{}, &struct{}{})
+}
+
+func dialDaemon() (*rpc.Client, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(), dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return jsonrpc.NewClient(conn), nil
+}
+
+type daemonService struct {
+	status DaemonStatus
+	stop   func()
+}
+
+func (d *daemonService) Status(_ struct{}, reply *DaemonStatus) error {
+	*reply = d.status
+	return nil
+}
+
+func (d *daemonService) Stop(_ struct{}, _ *struct{}) error {
+	if d.stop != nil {
+		// Let the reply go out before the listener closes.
+		go d.stop()
+	}
+	return nil
+}
+
+// taskService exposes a TodoRepository to one RPC client, one method per
+// repository method.
+type taskService struct {
+	repository TodoRepository
+	// writes counts writes made for every client, and own those made for
+	// this one. They all go through the daemon's own connection, which
+	// SQLite's data_version does not count.
+	writes *atomic.Int64
+	own    atomic.Int64
+}
+
+// wrote records a write once it has succeeded.
+func (s *taskService) wrote(err error) error {
+	if err == nil {
+		s.own.Add(1)
+		s.writes.Add(1)
+	}
+	return err
+}
+
+// Reply carries a call's result over the socket, or the error it failed
+// with.
+type Reply[T any] struct {
+	Value T
+	Err   *RemoteError `json:",omitempty"`
+}
+
+// set fills in the reply. The error goes in the reply rather than being
+// returned, so that its code and fields reach the client.
+func (r *Reply[T]) set(value T, err error) error {
+	r.Value, r.Err = value, remoteError(err)
+	return nil
+}
+
+// Codes of the errors a client rebuilds from a RemoteError.
+const (
+	codeNotFound  = "not-found"
+	codeConflict  = "conflict"
+	codeProtected = "protected"
+	codeBlocked   = "blocked"
+)
+
+// RemoteError is an error sent back to a client. The package's sentinel
+// and typed errors are identified by Code and carry their fields; any
+// other error only its message.
+type RemoteError struct {
+	Code     string `json:",omitempty"`
+	Message  string
+	Id       int        `json:",omitempty"`
+	Current  *data.Task `json:",omitempty"`
+	Blockers []int      `json:",omitempty"`
+}
+
+func remoteError(err error) *RemoteError {
+	if err == nil {
+		return nil
+	}
+	e := &RemoteError{Message: err.Error()}
+	var conflict *ConflictError
+	var protected *ProtectedError
+	var blocked *BlockedError
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		e.Code = codeNotFound
+	case errors.As(err, &conflict):
+		e.Code, e.Id, e.Current = codeConflict, conflict.Id, &conflict.Current
+	case errors.As(err, &protected):
+		e.Code, e.Id = codeProtected, protected.Id
+	case errors.As(err, &blocked):
+		e.Code, e.Id, e.Blockers = codeBlocked, blocked.Id, blocked.Blockers
+	}
+	return e
+}
+
+// rebuild returns the error the daemon sent back.
+func (e *RemoteError) rebuild() error {
+	switch e.Code {
+	case codeNotFound:
+		return ErrTaskNotFound
+	case codeConflict:
+		conflict := &ConflictError{Id: e.Id}
+		if e.Current != nil {
+			conflict.Current = *e.Current
+		}
+		return conflict
+	case codeProtected:
+		return &ProtectedError{Id: e.Id}
+	case codeBlocked:
+		return &BlockedError{Id: e.Id, Blockers: e.Blockers}
+	}
+	return errors.New(e.Message)
+}
+
+func (s *taskService) CreateTask(task data.Task, reply *Reply[int]) error {
+	id, err := s.repository.CreateTask(task)
+	return reply.set(id, s.wrote(err))
+}
+
+func (s *taskService) GetTask(id int, reply *Reply[data.Task]) error {
+	return reply.set(s.repository.GetTask(id))
+}
+
+func (s *taskService) FindTasks(opts ListOptions, reply *Reply[[]data.Task]) error {
+	return reply.set(s.repository.FindTasks(opts))
+}
+
+func (s *taskService) GetCompletedTasks(since time.Time, reply *Reply[[]data.Task]) error {
+	return reply.set(s.repository.GetCompletedTasks(since))
+}
+
+func (s *taskService) GetTaskHistory(id int, reply *Reply[[]data.Event]) error {
+	return reply.set(s.repository.GetTaskHistory(id))
+}
+
+func (s *taskService) UpdateTask(task data.Task, reply *Reply[struct{}]) error {
+	return reply.set(struct{}{}, s.wrote(s.repository.UpdateTask(task)))
+}
+
+func (s *taskService) UpdateTasks(tasks []data.Task, reply *Reply[struct{}]) error {
+	return reply.set(struct{}{}, s.wrote(s.repository.UpdateTasks(tasks)))
+}
+
+func (s *taskService) UpsertTasks(tasks []data.Task, reply *Reply[[]int]) error {
+	ids, err := s.repository.UpsertTasks(tasks)
+	return reply.set(ids, s.wrote(err))
+}
+
+func (s *taskService) DeleteTaskById(id int, reply *Reply[struct{}]) error {
+	return reply.set(struct{}{}, s.wrote(s.repository.DeleteTaskById(id)))
+}
+
+// DeleteArgs carries a DeleteTasks call over the socket.
+type DeleteArgs struct {
+	Ids   []int
+	Force bool
+}
+
+func (s *taskService) DeleteTasks(args DeleteArgs, reply *Reply[int]) error {
+	deleted, err := s.repository.DeleteTasks(args.Ids, args.Force)
+	return reply.set(deleted, s.wrote(err))
+}
+
+// LinkArgs carries a LinkTasks or UnlinkTasks call over the socket.
+type LinkArgs struct {
+	Blocker int
+	Blocked []int
+}
+
+func (s *taskService) LinkTasks(args LinkArgs, reply *Reply[struct{}]) error {
+	return reply.set(struct{}{}, s.wrote(s.repository.LinkTasks(args.Blocker, args.Blocked)))
+}
+
+func (s *taskService) UnlinkTasks(args LinkArgs, reply *Reply[struct{}]) error {
+	return reply.set(struct{}{}, s.wrote(s.repository.UnlinkTasks(args.Blocker, args.Blocked)))
+}
+
+func (s *taskService) GetDependencies(_ struct{}, reply *Reply[[]data.Dependency]) error {
+	return reply.set(s.repository.GetDependencies())
+}
+
+func (s *taskService) GetStatuses(_ struct{}, reply *Reply[[]data.Status]) error {
+	return reply.set(s.repository.GetStatuses())
+}
+
+func (s *taskService) SetStatuses(statuses []data.Status, reply *Reply[struct{}]) error {
+	return reply.set(struct{}{}, s.wrote(s.repository.SetStatuses(statuses)))
+}
+
+// DataVersion adds the writes made for other clients to the database's
+// data_version, so clients see changes made by each other as well as by
+// other processes. Like data_version, it leaves out the client's own.
+func (s *taskService) DataVersion(_ struct{}, reply *Reply[int64]) error {
+	v, err := s.repository.DataVersion()
+	return reply.set(v+s.writes.Load()-s.own.Load(), err)
+}
+
+// remoteTodoRepository is a TodoRepository backed by the daemon.
+type remoteTodoRepository struct {
+	client *rpc.Client
+}
+
+// call makes one RPC, returning its result or the error the daemon sent
+// back, rebuilt as the package's sentinel or typed error where it was one.
+func call[T any](r *remoteTodoRepository, method string, args any) (T, error) {
+	var reply Reply[T]
+	if err := r.client.Call("Tasks."+method, args, &reply); err != nil {
+		return reply.Value, err
+	}
+	if reply.Err != nil {
+		return reply.Value, reply.Err.rebuild()
+	}
+	return reply.Value, nil
+}
+
+func (r *remoteTodoRepository) SaveTask(title string, dueDate time.Time) error {
+	_, err := r.CreateTask(data.Task{Title: title, DueDate: dueDate})
+	return err
+}
+
+func (r *remoteTodoRepository) CreateTask(task data.Task) (int, error) {
+	return call[int](r, "CreateTask", task)
+}
+
+func (r *remoteTodoRepository) GetTask(id int) (data.Task, error) {
+	return call[data.Task](r, "GetTask", id)
+}
+
+func (r *remoteTodoRepository) GetTasks() ([]data.Task, error) {
+	return r.FindTasks(ListOptions{})
+}
+
+func (r *remoteTodoRepository) FindTasks(opts ListOptions) ([]data.Task, error) {
+	return call[[]data.Task](r, "FindTasks", opts)
+}
+
+func (r *remoteTodoRepository) GetCompletedTasks(since time.Time) ([]data.Task, error) {
+	return call[[]data.Task](r, "GetCompletedTasks", since)
+}
+
+func (r *remoteTodoRepository) GetTaskHistory(id int) ([]data.Event, error) {
+	return call[[]data.Event](r, "GetTaskHistory", id)
+}
+
+func (r *remoteTodoRepository) UpdateTask(task data.Task) error {
+	_, err := call[struct{}](r, "UpdateTask", task)
+	return err
+}
+
+func (r *remoteTodoRepository) UpdateTasks(tasks []data.Task) error {
+	_, err := call[struct{}](r, "UpdateTasks", tasks)
+	return err
+}
+
+func (r *remoteTodoRepository) UpsertTasks(tasks []data.Task) ([]int, error) {
+	return call[[]int](r, "UpsertTasks", tasks)
+}
+
+func (r *remoteTodoRepository) DeleteTaskById(id int) error {
+	_, err := call[struct{}](r, "DeleteTaskById", id)
+	return err
+}
+
+func (r *remoteTodoRepository) DeleteTasks(ids []int, force bool) (int, error) {
+	return call[int](r, "DeleteTasks", DeleteArgs{Ids: ids, Force: force})
+}
+
+func (r *remoteTodoRepository) LinkTasks(blocker int, blocked []int) error {
+	_, err := call[struct{}](r, "LinkTasks", LinkArgs{Blocker: blocker, Blocked: blocked})
+	return err
+}
+
+func (r *remoteTodoRepository) UnlinkTasks(blocker int, blocked []int) error {
+	_, err := call[struct{}](r, "UnlinkTasks", LinkArgs{Blocker: blocker, Blocked: blocked})
+	return err
+}
+
+func (r *remoteTodoRepository) GetDependencies() ([]data.Dependency, error) {
+	return call[[]data.Dependency](r, "GetDependencies", struct{}{})
+}
+
+func (r *remoteTodoRepository) GetStatuses() ([]data.Status, error) {
+	return call[[]data.Status](r, "GetStatuses", struct{}{})
+}
+
+func (r *remoteTodoRepository) SetStatuses(statuses []data.Status) error {
+	_, err := call[struct{}](r, "SetStatuses", statuses)
+	return err
+}
+
+func (r *remoteTodoRepository) DataVersion() (int64, error) {
+	return call[int64](r, "DataVersion", struct{}{})
+}
+
+// Close disconnects from the daemon, which keeps running.
+func (r *remoteTodoRepository) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,243 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDaemon serves a local repository on the test database's socket, as
+// `todo daemon run` does.
+func startDaemon(t *testing.T) (stopped <-chan struct{}) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "todo")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	// Unix socket paths are short, so keep them out of the long test TempDir.
+	t.Setenv("TODO_DB", filepath.Join(dir, "todo.sqlite"))
+
+	local := NewLocalRepository()
+	listener, err := net.Listen("unix", SocketPath())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, Serve(listener, local, func() { _ = listener.Close() }))
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		<-done
+		_ = local.Close()
+	})
+	return done
+}
+
+func Test_NewTodoRepository_Uses_Running_Daemon(t *testing.T) {
+	startDaemon(t)
+
+	repo := NewTodoRepository()
+	defer repo.Close()
+	_, remote := repo.(*remoteTodoRepository)
+	require.True(t, remote, "clients talk to the daemon when it is running")
+
+	due := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+	id, err := repo.CreateTask(data.Task{Title: "Over the socket", DueDate: due, Tags: []string{"rpc"}})
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveTask("Saved", time.Time{}))
+
+	task, err := repo.GetTask(id)
+	require.NoError(t, err)
+	assert.Equal(t, "Over the socket", task.Title)
+	assert.True(t, due.Equal(task.DueDate))
+	assert.Equal(t, []string{"rpc"}, task.Tags)
+
+	task.Complete = true
+	require.NoError(t, repo.UpdateTask(task))
+	completed, err := repo.GetCompletedTasks(due)
+	require.NoError(t, err)
+	assert.Len(t, completed, 1)
+
+	ids, err := repo.UpsertTasks([]data.Task{{Title: "Upserted"}})
+	require.NoError(t, err)
+	assert.Len(t, ids, 1)
+	require.NoError(t, repo.DeleteTaskById(ids[0]))
+	ids, err = repo.UpsertTasks([]data.Task{{Title: "Bulk 1"}, {Title: "Bulk 2"}})
+	require.NoError(t, err)
+	_, err = repo.DeleteTasks(ids, false)
+	require.NoError(t, err)
+
+	tasks, err := repo.FindTasks(ListOptions{Sort: data.SortByTitle})
+	require.NoError(t, err)
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, "Over the socket", tasks[0].Title)
+	}
+	require.NoError(t, repo.UpdateTasks(tasks))
+
+	history, err := repo.GetTaskHistory(id)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	statuses, err := repo.GetStatuses()
+	require.NoError(t, err)
+	require.NoError(t, repo.SetStatuses(append(statuses, data.Status{Name: "wontfix", Terminal: true})))
+	statuses, err = repo.GetStatuses()
+	require.NoError(t, err)
+	assert.Len(t, statuses, 5)
+
+	_, err = repo.GetTask(999)
+	assert.ErrorIs(t, err, ErrTaskNotFound, "sentinel errors survive the round trip")
+}
+
+func Test_Daemon_Status_And_Stop(t *testing.T) {
+	stopped := startDaemon(t)
+
+	status, err := GetDaemonStatus()
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), status.Pid)
+	assert.Equal(t, dbFilePath(), status.Database)
+
+	require.NoError(t, StopDaemon())
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+	_, err = GetDaemonStatus()
+	assert.Error(t, err)
+}
+
+func Test_NewTodoRepository_Falls_Back_Without_Daemon(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TODO_DB", filepath.Join(dir, "todo.sqlite"))
+	// A socket file left behind by a daemon that died.
+	require.NoError(t, os.WriteFile(SocketPath(), nil, 0o600))
+
+	repo := NewTodoRepository()
+	defer repo.Close()
+	_, local := repo.(*SqlLiteTodoRepository)
+	assert.True(t, local)
+}
+
+func Test_Daemon_DataVersion_Counts_Other_Clients_Writes(t *testing.T) {
+	startDaemon(t)
+	watcher := NewTodoRepository()
+	defer watcher.Close()
+	writer := NewTodoRepository()
+	defer writer.Close()
+
+	before, err := watcher.DataVersion()
+	require.NoError(t, err)
+	_, err = writer.CreateTask(data.Task{Title: "From another client"})
+	require.NoError(t, err)
+	after, err := watcher.DataVersion()
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+
+	_, err = writer.GetTasks()
+	require.NoError(t, err)
+	again, err := watcher.DataVersion()
+	require.NoError(t, err)
+	assert.Equal(t, after, again, "reads do not count")
+
+	seen, err := writer.DataVersion()
+	require.NoError(t, err)
+	_, err = watcher.CreateTask(data.Task{Title: "From the watcher"})
+	require.NoError(t, err)
+	own, err := watcher.DataVersion()
+	require.NoError(t, err)
+	assert.Equal(t, after, own, "a client's own writes do not count for it")
+	other, err := writer.DataVersion()
+	require.NoError(t, err)
+	assert.NotEqual(t, seen, other)
+}
+
+func Test_Daemon_Returns_Typed_Conflicts(t *testing.T) {
+	startDaemon(t)
+	repo := NewTodoRepository()
+	defer repo.Close()
+
+	id, err := repo.CreateTask(data.Task{Title: "Shared"})
+	require.NoError(t, err)
+	loaded, err := repo.GetTask(id)
+	require.NoError(t, err)
+
+	theirs := loaded
+	theirs.Title = "Theirs"
+	require.NoError(t, repo.UpdateTask(theirs))
+
+	loaded.Title = "Mine"
+	err = repo.UpdateTask(loaded)
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, id, conflict.Id)
+	assert.Equal(t, "Theirs", conflict.Current.Title)
+}
+
+func Test_Daemon_Returns_Typed_Protected_Errors(t *testing.T) {
+	startDaemon(t)
+	repo := NewTodoRepository()
+	defer repo.Close()
+
+	id, err := repo.CreateTask(data.Task{Title: "Keep", Protected: true})
+	require.NoError(t, err)
+
+	var protected *ProtectedError
+	require.ErrorAs(t, repo.DeleteTaskById(id), &protected)
+	assert.Equal(t, id, protected.Id)
+	_, err = repo.DeleteTasks([]int{id}, true)
+	require.NoError(t, err)
+}
+
+func Test_Daemon_Returns_Typed_Blocked_Errors(t *testing.T) {
+	startDaemon(t)
+	repo := NewTodoRepository()
+	defer repo.Close()
+
+	ids, err := repo.UpsertTasks([]data.Task{{Title: "First"}, {Title: "Second"}, {Title: "Third"}})
+	require.NoError(t, err)
+	require.NoError(t, repo.LinkTasks(ids[0], []int{ids[2]}))
+	require.NoError(t, repo.LinkTasks(ids[1], []int{ids[2]}))
+	deps, err := repo.GetDependencies()
+	require.NoError(t, err)
+	assert.Len(t, deps, 2)
+
+	task, err := repo.GetTask(ids[2])
+	require.NoError(t, err)
+	task.Complete = true
+	var blocked *BlockedError
+	require.ErrorAs(t, repo.UpdateTask(task), &blocked)
+	assert.Equal(t, []int{ids[0], ids[1]}, blocked.Blockers)
+
+	require.NoError(t, repo.UnlinkTasks(ids[0], []int{ids[2]}))
+	require.NoError(t, repo.UnlinkTasks(ids[1], []int{ids[2]}))
+	require.NoError(t, repo.UpdateTask(task))
+}
+
+func Test_RemoteError_Rebuilds_By_Code(t *testing.T) {
+	conflict := &ConflictError{Id: 3, Current: data.Task{Id: 3, Title: "Theirs"}}
+	blocked := &BlockedError{Id: 4, Blockers: []int{1, 2}}
+	for _, err := range []error{
+		ErrTaskNotFound,
+		conflict,
+		&ProtectedError{Id: 5},
+		blocked,
+	} {
+		assert.Equal(t, err.Error(), remoteError(err).rebuild().Error())
+	}
+	assert.Equal(t, conflict, remoteError(conflict).rebuild())
+	assert.Equal(t, blocked, remoteError(fmt.Errorf("saving: %w", blocked)).rebuild(), "wrapped errors keep their type")
+
+	plain := remoteError(errors.New("task 7 is protected"))
+	var protected *ProtectedError
+	assert.False(t, errors.As(plain.rebuild(), &protected), "only the code decides the type")
+	assert.Nil(t, remoteError(nil))
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ake3mio/go-todo-cli/internal/data"
@@ -23,40 +25,220 @@ func dbFilePath() string {
 	return "todo.sqlite"
 }
 func newDB() *sql.DB {
-	dsn := fmt.Sprintf("file:%s?mode=rwc&_busy_timeout=5000&_journal_mode=WAL", dbFilePath())
-	db, err := sql.Open("sqlite3", dsn)
+	path := dbFilePath()
+	_, statErr := os.Stat(path)
+	db, err := openDB(path)
 	if err != nil {
 		panic(err)
 	}
-	db.SetMaxOpenConns(1)
-	if err = db.Ping(); err != nil {
-		panic(err)
-	}
 
 	if _, err := db.ExecContext(context.TODO(), schema); err != nil {
 		panic(err)
 	}
+	if statErr == nil {
+		if err := backupBeforeMigrate(context.TODO(), db, path, time.Now()); err != nil {
+			panic(err)
+		}
+	}
+	if err := migrate(context.TODO(), db); err != nil {
+		panic(err)
+	}
 	return db
 }
 
+func openDB(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?mode=rwc&_busy_timeout=5000&_journal_mode=WAL", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// ListOptions controls the order FindTasks returns tasks in. The zero value
+// sorts by ascending due date.
+//
+// Long lists can be read a page at a time: set Limit, then pass the last
+// task of each page as After to get the next.
+type ListOptions struct {
+	Sort data.SortKey
+	Desc bool
+	// HideCompleted leaves out completed tasks.
+	HideCompleted bool
+	// After starts the list just past this task in the sort order.
+	After *data.Task
+	// Limit caps how many tasks are returned; zero means all of them.
+	Limit int
+}
+
+// sortColumn is one term of a sort order: the SQL expression, its
+// direction, and how to compute its value from a task for paging.
+type sortColumn struct {
+	expr  string
+	desc  bool
+	value func(data.Task) any
+}
+
+func (o ListOptions) columns() []sortColumn {
+	id := sortColumn{"id", false, func(t data.Task) any { return t.Id }}
+	due := sortColumn{"due_date", false, func(t data.Task) any { return t.DueDate.UTC().Unix() }}
+	switch o.Sort {
+	case data.SortByCreated:
+		id.desc = o.Desc
+		return []sortColumn{{"created_at", o.Desc, func(t data.Task) any { return t.CreatedAt.UTC().Unix() }}, id}
+	case data.SortByPriority:
+		return []sortColumn{
+			{"priority = ''", false, func(t data.Task) any { return t.Priority == "" }},
+			{"priority", o.Desc, func(t data.Task) any { return t.Priority }},
+			due, id,
+		}
+	case data.SortByTitle:
+		return []sortColumn{{"title COLLATE NOCASE", o.Desc, func(t data.Task) any { return t.Title }}, id}
+	case data.SortByCompletion:
+		return []sortColumn{{"complete", o.Desc, func(t data.Task) any { return t.Complete }}, due, id}
+	default:
+		due.desc = o.Desc
+		return []sortColumn{{fmt.Sprintf("due_date = %d", noDueDate), false, func(t data.Task) any { return t.DueDate.IsZero() }}, due, id}
+	}
+}
+
+func (o ListOptions) orderBy() string {
+	terms := make([]string, 0, 4)
+	for _, c := range o.columns() {
+		direction := "ASC"
+		if c.desc {
+			direction = "DESC"
+		}
+		terms = append(terms, c.expr+" "+direction)
+	}
+	return strings.Join(terms, ", ")
+}
+
+// where returns the filter for o, with its arguments. Paging past After
+// compares the sort columns in order, which the trailing id makes exact.
+func (o ListOptions) where() (string, []any) {
+	var conditions []string
+	var args []any
+	if o.HideCompleted {
+		conditions = append(conditions, "NOT complete")
+	}
+	if o.After != nil {
+		var alternatives []string
+		columns := o.columns()
+		for i, c := range columns {
+			var terms []string
+			for _, earlier := range columns[:i] {
+				terms = append(terms, "("+earlier.expr+") = ?")
+				args = append(args, earlier.value(*o.After))
+			}
+			op := " > ?"
+			if c.desc {
+				op = " < ?"
+			}
+			terms = append(terms, "("+c.expr+")"+op)
+			args = append(args, c.value(*o.After))
+			alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ErrTaskNotFound is returned when a task Id does not exist.
+var ErrTaskNotFound = errors.New("task not found")
+
+// ConflictError is returned when an update was based on an older version of
+// a task than the one stored. Current holds the stored task.
+type ConflictError struct {
+	Id      int
+	Current data.Task
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("task %d was changed elsewhere", e.Id)
+}
+
+// ProtectedError is returned when a delete that is not forced includes a
+// protected task.
+type ProtectedError struct {
+	Id int
+}
+
+func (e *ProtectedError) Error() string {
+	return fmt.Sprintf("task %d is protected", e.Id)
+}
+
+// noDueDate is how a zero DueDate is stored; such tasks sort after dated ones.
+var noDueDate = time.Time{}.Unix()
+
 type TodoRepository interface {
 	SaveTask(task string, dueDate time.Time) error
+	CreateTask(task data.Task) (int, error)
+	GetTask(id int) (data.Task, error)
 	GetTasks() ([]data.Task, error)
+	FindTasks(opts ListOptions) ([]data.Task, error)
+	GetCompletedTasks(since time.Time) ([]data.Task, error)
+	GetTaskHistory(id int) ([]data.Event, error)
+	// UpdateTask and UpdateTasks return a *ConflictError, and change
+	// nothing, when they would change a task whose Version is behind the
+	// stored one, and a *BlockedError when they would complete a task that
+	// waits on an open one.
 	UpdateTask(task data.Task) error
 	UpdateTasks(tasks []data.Task) error
+	UpsertTasks(tasks []data.Task) ([]int, error)
+	// DeleteTaskById and DeleteTasks return a *ProtectedError, and delete
+	// nothing, when a task is protected, unless DeleteTasks is forced.
 	DeleteTaskById(id int) error
+	// DeleteTasks deletes every task in ids in one transaction and returns
+	// how many it deleted. Ids that do not exist are skipped.
+	DeleteTasks(ids []int, force bool) (int, error)
+	// DataVersion returns a number that changes whenever another connection
+	// commits to the database, so long-lived views can notice external edits.
+	DataVersion() (int64, error)
+	// LinkTasks records, in one transaction, that blocker has to be
+	// completed before each of blocked. It changes nothing when one of the
+	// tasks does not exist or a link would make a task wait on itself,
+	// directly or through other tasks. New links are recorded in both
+	// tasks' history.
+	LinkTasks(blocker int, blocked []int) error
+	// UnlinkTasks removes the links from blocker to each of blocked in one
+	// transaction, recording the removals in both tasks' history.
+	UnlinkTasks(blocker int, blocked []int) error
+	// GetDependencies returns every link between tasks.
+	GetDependencies() ([]data.Dependency, error)
+	// GetStatuses returns the workflow in board order.
+	GetStatuses() ([]data.Status, error)
+	// SetStatuses replaces the workflow. It fails, changing nothing, when
+	// the statuses are not valid or would drop one that tasks are in, and
+	// with a *BlockedError when it would complete a task that waits on an
+	// open one.
+	SetStatuses(statuses []data.Status) error
 	Close() error
 }
 
 type SqlLiteTodoRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	now func() time.Time
+}
+
+func (t *SqlLiteTodoRepository) SaveTask(title string, dueDate time.Time) error {
+	_, err := t.CreateTask(data.Task{Title: title, DueDate: dueDate})
+	return err
 }
 
-func (t *SqlLiteTodoRepository) SaveTask(title string, dueDate time.Time) (err error) {
+func (t *SqlLiteTodoRepository) CreateTask(task data.Task) (id int, err error) {
 	ctx := context.TODO()
 	tx, err := t.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if err != nil {
@@ -64,40 +246,181 @@ func (t *SqlLiteTodoRepository) SaveTask(title string, dueDate time.Time) (err e
 		}
 	}()
 
-	_, err = tx.ExecContext(ctx, `INSERT INTO tasks (title, due_date) VALUES (?, ?)`, title, dueDate.UTC().Unix())
+	if id, err = t.insertTaskTx(ctx, tx, task); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	return id, err
+}
+
+// UpsertTasks creates tasks without an Id and updates the rest in a single
+// transaction, returning the Id of every task in order. Tasks whose Id no
+// longer exists are created afresh. A data.PendingParent ParentId must refer
+// to an earlier task in the batch.
+func (t *SqlLiteTodoRepository) UpsertTasks(tasks []data.Task) (ids []int, err error) {
+	ctx := context.TODO()
+	tx, err := t.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for i, task := range tasks {
+		if index, ok := data.PendingIndex(task.ParentId); ok {
+			if index >= i {
+				err = fmt.Errorf("task %q: parent must come before its subtasks", task.Title)
+				return nil, err
+			}
+			task.ParentId = ids[index]
+		}
+		if task.Id != 0 {
+			if _, err = getTaskTx(ctx, tx, task.Id); err == nil {
+				if err = t.updateTaskTx(ctx, tx, task); err != nil {
+					return nil, err
+				}
+				ids = append(ids, task.Id)
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			task.Id = 0
+		}
+		id, err := t.insertTaskTx(ctx, tx, task)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
 	}
 
 	err = tx.Commit()
-	return err
+	return ids, err
+}
+
+// insertTaskTx stores a new task. CreatedAt and CompletedAt are kept when
+// set, so imported tasks retain their original dates.
+func (t *SqlLiteTodoRepository) insertTaskTx(ctx context.Context, tx *sql.Tx, task data.Task) (int, error) {
+	now := t.now().UTC().Unix()
+	createdAt := now
+	if !task.CreatedAt.IsZero() {
+		createdAt = task.CreatedAt.UTC().Unix()
+	}
+	statuses, err := getStatusesTx(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if task, err = statuses.Reconcile(data.Task{}, task); err != nil {
+		return 0, err
+	}
+	var completedAt any
+	if task.Complete {
+		completedAt = now
+		if !task.CompletedAt.IsZero() {
+			completedAt = task.CompletedAt.UTC().Unix()
+		}
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO tasks (title, complete, status, due_date, priority, project, tags, notes, uid, parent_id, protected, created_at, updated_at, completed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		task.Title, task.Complete, task.Status, task.DueDate.UTC().Unix(), task.Priority, task.Project, joinTags(task.Tags), task.Notes, task.UID, task.ParentId, task.Protected, createdAt, now, completedAt)
+	if err != nil {
+		return 0, err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err = t.recordEvent(ctx, tx, int(lastId), data.EventCreated, data.CreatedChanges(task)); err != nil {
+		return 0, err
+	}
+	return int(lastId), nil
+}
+
+// GetTask returns the task with id, or ErrTaskNotFound.
+func (t *SqlLiteTodoRepository) GetTask(id int) (data.Task, error) {
+	ctx := context.TODO()
+	rows, err := t.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return data.Task{}, err
+	}
+	if len(tasks) == 0 {
+		return data.Task{}, ErrTaskNotFound
+	}
+	return tasks[0], nil
 }
 
 func (t *SqlLiteTodoRepository) GetTasks() ([]data.Task, error) {
+	return t.FindTasks(ListOptions{})
+}
+
+func (t *SqlLiteTodoRepository) FindTasks(opts ListOptions) ([]data.Task, error) {
 	ctx := context.TODO()
-	rows, err := t.db.QueryContext(ctx, `SELECT id, title, complete, due_date FROM tasks ORDER BY due_date`)
-	var tasks []data.Task
+	where, args := opts.where()
+	query := `SELECT ` + taskColumns + ` FROM tasks` + where + ` ORDER BY ` + opts.orderBy()
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+	rows, err := t.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return tasks, err
+		return nil, err
 	}
+	return scanTasks(rows)
+}
+
+// GetCompletedTasks returns tasks completed at or after since, oldest
+// completion first.
+func (t *SqlLiteTodoRepository) GetCompletedTasks(since time.Time) ([]data.Task, error) {
+	ctx := context.TODO()
+	rows, err := t.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE complete AND completed_at >= ? ORDER BY completed_at, id`, since.UTC().Unix())
+	if err != nil {
+		return nil, err
+	}
+	return scanTasks(rows)
+}
+
+const taskColumns = `id, title, complete, status, due_date, priority, project, tags, notes, uid, parent_id, version, protected, created_at, updated_at, completed_at`
+
+func scanTasks(rows *sql.Rows) ([]data.Task, error) {
+	var tasks []data.Task
 	defer rows.Close()
 	for rows.Next() {
-		var id int
-		var title string
-		var complete bool
+		var id, parentId, version int
+		var title, status string
+		var complete, protected bool
 		var dueDate time.Time
-		if err := rows.Scan(&id, &title, &complete, &dueDate); err != nil {
+		var priority, project, tags, notes, uid string
+		var createdAt, updatedAt, completedAt sql.NullTime
+		if err := rows.Scan(&id, &title, &complete, &status, &dueDate, &priority, &project, &tags, &notes, &uid, &parentId, &version, &protected, &createdAt, &updatedAt, &completedAt); err != nil {
 			return tasks, err
 		}
 		task := data.Task{
-			Id:       id,
-			Title:    title,
-			Complete: complete,
-			DueDate:  dueDate,
+			Id:          id,
+			Title:       title,
+			Complete:    complete,
+			Status:      status,
+			DueDate:     dueDate,
+			Priority:    priority,
+			Project:     project,
+			Tags:        splitTags(tags),
+			Notes:       notes,
+			UID:         uid,
+			ParentId:    parentId,
+			Version:     version,
+			Protected:   protected,
+			CreatedAt:   createdAt.Time,
+			UpdatedAt:   updatedAt.Time,
+			CompletedAt: completedAt.Time,
 		}
 		tasks = append(tasks, task)
 	}
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 func (t *SqlLiteTodoRepository) UpdateTask(task data.Task) error {
@@ -112,14 +435,18 @@ func (t *SqlLiteTodoRepository) UpdateTask(task data.Task) error {
 		}
 	}()
 
-	_, err = tx.ExecContext(ctx, `UPDATE tasks SET title = ?, complete = ?, due_date = ? WHERE id=?`, task.Title, task.Complete, task.DueDate.UTC().Unix(), task.Id)
-	if err != nil {
+	if err = t.updateTaskTx(ctx, tx, task); err != nil {
 		return err
 	}
 
 	err = tx.Commit()
 	return err
 }
+
+// UpdateTasks writes every task that differs from its stored row in one
+// transaction. The stored rows are read in batches and the writes reuse
+// prepared statements, so tasks that have not changed cost little; callers
+// should still pass only the tasks they changed.
 func (t *SqlLiteTodoRepository) UpdateTasks(tasks []data.Task) error {
 	ctx := context.TODO()
 	tx, err := t.db.BeginTx(ctx, nil)
@@ -132,22 +459,45 @@ func (t *SqlLiteTodoRepository) UpdateTasks(tasks []data.Task) error {
 		}
 	}()
 
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.Id
+	}
+	stored, err := getTasksTx(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+	w, err := t.prepareWriter(ctx, tx)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
 	for _, task := range tasks {
-		_, err = tx.ExecContext(ctx, `UPDATE tasks SET title = ?, complete = ?, due_date = ? WHERE id=?`, task.Title, task.Complete, task.DueDate.UTC().Unix(), task.Id)
-		if err != nil {
-			_ = tx.Rollback()
+		old, ok := stored[task.Id]
+		if !ok {
+			continue
+		}
+		if err = w.write(ctx, old, task); err != nil {
 			return err
 		}
 	}
+	if err = w.checkBlocked(ctx); err != nil {
+		return err
+	}
 
 	err = tx.Commit()
 	return err
 }
 func (t *SqlLiteTodoRepository) DeleteTaskById(id int) error {
+	_, err := t.DeleteTasks([]int{id}, false)
+	return err
+}
+
+func (t *SqlLiteTodoRepository) DeleteTasks(ids []int, force bool) (deleted int, err error) {
 	ctx := context.TODO()
 	tx, err := t.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if err != nil {
@@ -155,20 +505,121 @@ func (t *SqlLiteTodoRepository) DeleteTaskById(id int) error {
 		}
 	}()
 
-	_, err = tx.ExecContext(ctx, `DELETE FROM tasks WHERE id=?`, id)
+	for _, id := range ids {
+		var ok bool
+		if ok, err = t.deleteTaskTx(ctx, tx, id, force); err != nil {
+			return 0, err
+		}
+		if ok {
+			deleted++
+		}
+	}
+
+	err = tx.Commit()
+	return deleted, err
+}
+
+// deleteTaskTx deletes the task with id, recording its final values and
+// the removal of its links, promotes its subtasks and reports whether there
+// was a task to delete. A task that no longer exists is left alone; a
+// protected one is refused unless force is set.
+func (t *SqlLiteTodoRepository) deleteTaskTx(ctx context.Context, tx *sql.Tx, id int, force bool) (bool, error) {
+	old, err := getTaskTx(ctx, tx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
+		return false, err
+	}
+	if old.Protected && !force {
+		return false, &ProtectedError{Id: id}
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM tasks WHERE id=?`, id); err != nil {
+		return false, err
+	}
+	if err = t.unlinkTaskTx(ctx, tx, id); err != nil {
+		return false, err
+	}
+	if err = t.recordEvent(ctx, tx, id, data.EventDeleted, data.DeletedChanges(old)); err != nil {
+		return false, err
+	}
+	return true, t.promoteSubtasksTx(ctx, tx, id)
+}
+
+// updateTaskTx writes task over its stored row as taskWriter.write does.
+// Tasks that no longer exist are left alone.
+func (t *SqlLiteTodoRepository) updateTaskTx(ctx context.Context, tx *sql.Tx, task data.Task) error {
+	old, err := getTaskTx(ctx, tx, task.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	w, err := t.prepareWriter(ctx, tx)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := w.write(ctx, old, task); err != nil {
 		return err
 	}
+	return w.checkBlocked(ctx)
+}
 
-	err = tx.Commit()
-	return err
+// promoteSubtasksTx makes the subtasks of a deleted task top-level tasks,
+// recording the change in each one's history.
+func (t *SqlLiteTodoRepository) promoteSubtasksTx(ctx context.Context, tx *sql.Tx, parentId int) error {
+	rows, err := tx.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE parent_id = ? ORDER BY id`, parentId)
+	if err != nil {
+		return err
+	}
+	subtasks, err := scanTasks(rows)
+	if err != nil {
+		return err
+	}
+	for _, task := range subtasks {
+		task.ParentId = 0
+		if err := t.updateTaskTx(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+func splitTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
+
+// DataVersion reports SQLite's data_version, which moves when any other
+// connection commits. This repository's own writes leave it unchanged.
+func (t *SqlLiteTodoRepository) DataVersion() (version int64, err error) {
+	err = t.db.QueryRowContext(context.TODO(), `PRAGMA data_version`).Scan(&version)
+	return version, err
 }
 
 func (t *SqlLiteTodoRepository) Close() error {
 	return t.db.Close()
 }
 
+// NewTodoRepository connects to the daemon when one is running for the
+// database, and otherwise opens the database directly.
 func NewTodoRepository() TodoRepository {
-	var repository TodoRepository = &SqlLiteTodoRepository{db: newDB()}
+	if client, err := dialDaemon(); err == nil {
+		return &remoteTodoRepository{client: client}
+	}
+	return NewLocalRepository()
+}
+
+// NewLocalRepository opens the database directly, migrating it if needed.
+func NewLocalRepository() TodoRepository {
+	var repository TodoRepository = &SqlLiteTodoRepository{db: newDB(), now: time.Now}
 	return repository
 }
@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"context"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -164,3 +166,832 @@ func Test_DeleteTaskById_Removes_Row(t *testing.T) {
 		cleanup(repo)
 	})
 }
+
+func Test_DeleteTasks_Removes_All_In_One_Go(t *testing.T) {
+	repo := mustNewRepo(t)
+	t.Cleanup(func() { cleanup(repo) })
+
+	ids, err := (*repo).UpsertTasks([]data.Task{{Title: "A"}, {Title: "B"}, {Title: "C"}})
+	assert.Nil(t, err)
+	parent := ids[0]
+	child, err := (*repo).CreateTask(data.Task{Title: "A.1", ParentId: parent})
+	assert.Nil(t, err)
+
+	deleted, err := (*repo).DeleteTasks([]int{ids[0], ids[2], 999}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted, "missing ids are skipped and not counted")
+
+	tasks, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+		if task.Id == child {
+			assert.Zero(t, task.ParentId, "subtasks of deleted tasks are promoted")
+		}
+	}
+	assert.ElementsMatch(t, []string{"B", "A.1"}, titles)
+
+	history, err := (*repo).GetTaskHistory(ids[2])
+	assert.Nil(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, data.EventDeleted, history[1].Kind)
+	}
+}
+
+func Test_DeleteTasks_Refuses_Protected_Tasks_Unless_Forced(t *testing.T) {
+	repo := mustNewRepo(t)
+	t.Cleanup(func() { cleanup(repo) })
+
+	open, err := (*repo).CreateTask(data.Task{Title: "Open"})
+	assert.Nil(t, err)
+	kept, err := (*repo).CreateTask(data.Task{Title: "Kept", Protected: true})
+	assert.Nil(t, err)
+
+	_, err = (*repo).DeleteTasks([]int{open, kept}, false)
+	var protected *ProtectedError
+	if assert.ErrorAs(t, err, &protected) {
+		assert.Equal(t, kept, protected.Id)
+	}
+	err = (*repo).DeleteTaskById(kept)
+	assert.ErrorAs(t, err, &protected)
+	tasks, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	assert.Len(t, tasks, 2, "nothing is deleted when one task is protected")
+
+	task, err := (*repo).GetTask(kept)
+	assert.Nil(t, err)
+	assert.True(t, task.Protected)
+	task.Protected = false
+	assert.Nil(t, (*repo).UpdateTasks([]data.Task{task}))
+	assert.Nil(t, (*repo).DeleteTaskById(kept))
+
+	_, err = (*repo).CreateTask(data.Task{Title: "Forced", Protected: true})
+	assert.Nil(t, err)
+	_, err = (*repo).DeleteTasks([]int{open}, true)
+	assert.Nil(t, err)
+	tasks, err = (*repo).GetTasks()
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "Forced", tasks[0].Title)
+	}
+}
+
+func Test_CreateTask_Persists_Metadata(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	d := time.Date(2025, time.September, 28, 0, 0, 0, 0, time.UTC)
+	id, err := (*repo).CreateTask(data.Task{Title: "Tagged", DueDate: d, Priority: "A", Project: "home", Tags: []string{"errand", "weekend"}, Notes: "bring bags", UID: "abc@example.com"})
+	assert.Nil(t, err)
+
+	got, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, id, got[0].Id)
+		assert.Equal(t, "A", got[0].Priority)
+		assert.Equal(t, "home", got[0].Project)
+		assert.Equal(t, []string{"errand", "weekend"}, got[0].Tags)
+		assert.Equal(t, "bring bags", got[0].Notes)
+		assert.Equal(t, "abc@example.com", got[0].UID)
+	}
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_FindTasks_Sorts_By_Key_And_Direction(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	d1 := time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(2025, time.September, 28, 0, 0, 0, 0, time.UTC)
+	d3 := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := (*repo).CreateTask(data.Task{Title: "banana", DueDate: d1, Priority: "B"})
+	assert.Nil(t, err)
+	_, err = (*repo).CreateTask(data.Task{Title: "Apple", DueDate: d2, Complete: true})
+	assert.Nil(t, err)
+	_, err = (*repo).CreateTask(data.Task{Title: "cherry", DueDate: d3, Priority: "A"})
+	assert.Nil(t, err)
+
+	titles := func(opts ListOptions) []string {
+		tasks, err := (*repo).FindTasks(opts)
+		assert.Nil(t, err)
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"Apple", "banana", "cherry"}, titles(ListOptions{}))
+	assert.Equal(t, []string{"cherry", "banana", "Apple"}, titles(ListOptions{Sort: data.SortByDue, Desc: true}))
+	assert.Equal(t, []string{"banana", "Apple", "cherry"}, titles(ListOptions{Sort: data.SortByCreated}))
+	assert.Equal(t, []string{"cherry", "banana", "Apple"}, titles(ListOptions{Sort: data.SortByPriority}))
+	assert.Equal(t, []string{"banana", "cherry", "Apple"}, titles(ListOptions{Sort: data.SortByPriority, Desc: true}))
+	assert.Equal(t, []string{"Apple", "banana", "cherry"}, titles(ListOptions{Sort: data.SortByTitle}))
+	assert.Equal(t, []string{"banana", "cherry", "Apple"}, titles(ListOptions{Sort: data.SortByCompletion}))
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_FindTasks_Pages_Match_Full_List(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	day := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	var tasks []data.Task
+	for i := 0; i < 23; i++ {
+		task := data.Task{
+			Title:    []string{"apple", "Apple", "banana", "cherry"}[i%4],
+			Complete: i%3 == 0,
+			Priority: []string{"", "A", "B"}[i%3],
+		}
+		if i%5 != 0 {
+			task.DueDate = day.AddDate(0, 0, i%4)
+		}
+		tasks = append(tasks, task)
+	}
+	_, err := (*repo).UpsertTasks(tasks)
+	assert.Nil(t, err)
+
+	ids := func(tasks []data.Task) []int {
+		var out []int
+		for _, task := range tasks {
+			out = append(out, task.Id)
+		}
+		return out
+	}
+
+	for _, sort := range []data.SortKey{data.SortByDue, data.SortByCreated, data.SortByPriority, data.SortByTitle, data.SortByCompletion} {
+		for _, opts := range []ListOptions{{Sort: sort}, {Sort: sort, Desc: true}, {Sort: sort, HideCompleted: true}} {
+			all, err := (*repo).FindTasks(opts)
+			assert.Nil(t, err)
+
+			var paged []data.Task
+			page := opts
+			page.Limit = 4
+			for {
+				tasks, err := (*repo).FindTasks(page)
+				assert.Nil(t, err)
+				assert.LessOrEqual(t, len(tasks), 4)
+				paged = append(paged, tasks...)
+				if len(tasks) < page.Limit {
+					break
+				}
+				page.After = &tasks[len(tasks)-1]
+			}
+			assert.Equal(t, ids(all), ids(paged), "%+v", opts)
+		}
+	}
+
+	open, err := (*repo).FindTasks(ListOptions{HideCompleted: true})
+	assert.Nil(t, err)
+	assert.Len(t, open, 15)
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_NewTodoRepository_Migrates_To_Latest_Version(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	names, err := migrations()
+	assert.Nil(t, err)
+	version, err := schemaVersion(context.Background(), (*repo).(*SqlLiteTodoRepository).db)
+	assert.Nil(t, err)
+	assert.Equal(t, len(names), version)
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func withClock(repo *TodoRepository, now *time.Time) {
+	(*repo).(*SqlLiteTodoRepository).now = func() time.Time { return *now }
+}
+
+func Test_Timestamps_Are_Maintained_By_Repository(t *testing.T) {
+	repo := mustNewRepo(t)
+	clock := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+	withClock(repo, &clock)
+
+	d := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, (*repo).SaveTask("Stamped", d))
+
+	tasks, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	if !assert.Len(t, tasks, 1) {
+		return
+	}
+	task := tasks[0]
+	assert.True(t, clock.Equal(task.CreatedAt))
+	assert.True(t, clock.Equal(task.UpdatedAt))
+	assert.True(t, task.CompletedAt.IsZero())
+
+	clock = clock.Add(time.Hour)
+	assert.Nil(t, (*repo).UpdateTasks(tasks))
+	after, _ := (*repo).GetTasks()
+	assert.True(t, task.UpdatedAt.Equal(after[0].UpdatedAt), "saving an unchanged task must not bump updated_at")
+
+	completedAt := clock
+	task.Complete = true
+	assert.Nil(t, (*repo).UpdateTask(task))
+	after, _ = (*repo).GetTasks()
+	assert.True(t, completedAt.Equal(after[0].CompletedAt))
+	assert.True(t, completedAt.Equal(after[0].UpdatedAt))
+
+	clock = clock.Add(time.Hour)
+	task = after[0]
+	task.Title = "Renamed"
+	assert.Nil(t, (*repo).UpdateTask(task))
+	after, _ = (*repo).GetTasks()
+	assert.True(t, completedAt.Equal(after[0].CompletedAt), "editing a completed task keeps its completion time")
+	assert.True(t, clock.Equal(after[0].UpdatedAt))
+
+	task = after[0]
+	task.Complete = false
+	assert.Nil(t, (*repo).UpdateTask(task))
+	after, _ = (*repo).GetTasks()
+	assert.True(t, after[0].CompletedAt.IsZero(), "reopening clears the completion time")
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_GetCompletedTasks_Returns_Completions_Since_In_Order(t *testing.T) {
+	repo := mustNewRepo(t)
+	clock := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+	withClock(repo, &clock)
+
+	d := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+	for _, title := range []string{"old", "second", "first", "open"} {
+		assert.Nil(t, (*repo).SaveTask(title, d))
+	}
+	tasks, _ := (*repo).GetTasks()
+	byTitle := map[string]data.Task{}
+	for _, task := range tasks {
+		byTitle[task.Title] = task
+	}
+
+	complete := func(title string, at time.Time) {
+		clock = at
+		task := byTitle[title]
+		task.Complete = true
+		assert.Nil(t, (*repo).UpdateTask(task))
+	}
+	complete("old", time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC))
+	complete("first", time.Date(2025, time.October, 2, 0, 0, 0, 0, time.UTC))
+	complete("second", time.Date(2025, time.October, 3, 0, 0, 0, 0, time.UTC))
+
+	got, err := (*repo).GetCompletedTasks(time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "first", got[0].Title)
+		assert.Equal(t, "second", got[1].Title)
+	}
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_Deleted_Task_Ids_Are_Not_Reused(t *testing.T) {
+	repo := mustNewRepo(t)
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+	d := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+
+	_, err := (*repo).CreateTask(data.Task{Title: "Keep", DueDate: d})
+	assert.Nil(t, err)
+	old, err := (*repo).CreateTask(data.Task{Title: "Old secret", DueDate: d})
+	assert.Nil(t, err)
+	assert.Nil(t, (*repo).DeleteTaskById(old))
+
+	id, err := (*repo).CreateTask(data.Task{Title: "New", DueDate: d})
+	assert.Nil(t, err)
+	assert.Greater(t, id, old)
+
+	events, err := (*repo).GetTaskHistory(id)
+	assert.Nil(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, data.EventCreated, events[0].Kind)
+	}
+	events, err = (*repo).GetTaskHistory(old)
+	assert.Nil(t, err)
+	assert.Len(t, events, 2, "the deleted task keeps its own history")
+}
+
+func Test_Migration_Starts_Ids_Past_Deleted_Tasks(t *testing.T) {
+	ctx := context.TODO()
+	db, err := openDB(filepath.Join(t.TempDir(), "todo.sqlite"))
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.ExecContext(ctx, schema)
+	assert.Nil(t, err)
+
+	// A database from before ids were kept monotonic, whose highest task
+	// was deleted.
+	names, err := migrations()
+	assert.Nil(t, err)
+	for i, name := range names[:10] {
+		script, _ := migrationFiles.ReadFile(name)
+		assert.Nil(t, applyMigration(ctx, db, string(script), i+1))
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO tasks (id, title, due_date) VALUES (1, 'Keep', '2025-10-05')`)
+	assert.Nil(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO task_events (task_id, kind, at) VALUES (1, 'created', '2025-10-01'), (2, 'created', '2025-10-01'), (2, 'deleted', '2025-10-02')`)
+	assert.Nil(t, err)
+
+	assert.Nil(t, migrate(ctx, db))
+	result, err := db.ExecContext(ctx, `INSERT INTO tasks (title, due_date) VALUES ('New', '2025-10-05')`)
+	assert.Nil(t, err)
+	id, _ := result.LastInsertId()
+	assert.Equal(t, int64(3), id)
+}
+
+func Test_GetTaskHistory_Records_Create_Update_Delete(t *testing.T) {
+	repo := mustNewRepo(t)
+	clock := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+	withClock(repo, &clock)
+
+	d := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+	id, err := (*repo).CreateTask(data.Task{Title: "Draft", DueDate: d})
+	assert.Nil(t, err)
+
+	tasks, _ := (*repo).GetTasks()
+	task := tasks[0]
+	assert.Nil(t, (*repo).UpdateTasks(tasks), "unchanged tasks record nothing")
+
+	clock = clock.Add(time.Hour)
+	task.Title = "Final"
+	task.DueDate = d.AddDate(0, 0, 1)
+	assert.Nil(t, (*repo).UpdateTask(task))
+
+	clock = clock.Add(time.Hour)
+	assert.Nil(t, (*repo).DeleteTaskById(id))
+
+	events, err := (*repo).GetTaskHistory(id)
+	assert.Nil(t, err)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, data.EventCreated, events[0].Kind)
+		assert.Equal(t, []data.Change{{Field: "title", New: "Draft"}, {Field: "status", New: "todo"}, {Field: "due", New: "2025-10-05"}}, events[0].Changes)
+
+		assert.Equal(t, data.EventUpdated, events[1].Kind)
+		assert.True(t, time.Date(2025, time.October, 1, 10, 0, 0, 0, time.UTC).Equal(events[1].At))
+		assert.Equal(t, []data.Change{
+			{Field: "title", Old: "Draft", New: "Final"},
+			{Field: "due", Old: "2025-10-05", New: "2025-10-06"},
+		}, events[1].Changes)
+
+		assert.Equal(t, data.EventDeleted, events[2].Kind)
+		assert.Equal(t, id, events[2].TaskId)
+	}
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_TaskEvents_Are_Append_Only(t *testing.T) {
+	repo := mustNewRepo(t)
+	assert.Nil(t, (*repo).SaveTask("T", time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)))
+
+	db := (*repo).(*SqlLiteTodoRepository).db
+	_, err := db.Exec(`UPDATE task_events SET kind = 'tampered'`)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = db.Exec(`DELETE FROM task_events`)
+	assert.ErrorContains(t, err, "append-only")
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_UpsertTasks_Creates_And_Updates_In_One_Call(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	d := time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC)
+	id, err := (*repo).CreateTask(data.Task{Title: "Existing", DueDate: d})
+	assert.Nil(t, err)
+
+	created := time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+	completed := time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)
+	ids, err := (*repo).UpsertTasks([]data.Task{
+		{Id: id, Title: "Existing, renamed", DueDate: d},
+		{Id: 999, Title: "Stale id", DueDate: d},
+		{Title: "Imported", Complete: true, CreatedAt: created, CompletedAt: completed},
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, ids, 3) {
+		assert.Equal(t, id, ids[0])
+		assert.NotEqual(t, 999, ids[1])
+	}
+
+	tasks, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 3) {
+		assert.Equal(t, "Existing, renamed", tasks[0].Title)
+		assert.Equal(t, "Imported", tasks[2].Title, "undated tasks sort last")
+		assert.True(t, tasks[2].DueDate.IsZero())
+		assert.True(t, created.Equal(tasks[2].CreatedAt), "imported creation date is kept")
+		assert.True(t, completed.Equal(tasks[2].CompletedAt), "imported completion date is kept")
+	}
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_UpsertTasks_Resolves_Pending_Parents(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	ids, err := (*repo).UpsertTasks([]data.Task{
+		{Title: "Parent"},
+		{Title: "Child", ParentId: data.PendingParent(0)},
+		{Title: "Grandchild", ParentId: data.PendingParent(1)},
+	})
+	assert.Nil(t, err)
+
+	tasks, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 3) {
+		assert.Equal(t, 0, tasks[0].ParentId)
+		assert.Equal(t, ids[0], tasks[1].ParentId)
+		assert.Equal(t, ids[1], tasks[2].ParentId)
+	}
+
+	_, err = (*repo).UpsertTasks([]data.Task{{Title: "Orphan", ParentId: data.PendingParent(0)}})
+	assert.ErrorContains(t, err, "parent must come before")
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_DeleteTaskById_Promotes_Subtasks(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	parent, err := (*repo).CreateTask(data.Task{Title: "Parent"})
+	assert.Nil(t, err)
+	child, err := (*repo).CreateTask(data.Task{Title: "Child", ParentId: parent})
+	assert.Nil(t, err)
+
+	assert.Nil(t, (*repo).DeleteTaskById(parent))
+
+	tasks, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, 0, tasks[0].ParentId)
+	}
+	history, err := (*repo).GetTaskHistory(child)
+	assert.Nil(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, []data.Change{{Field: "parent", Old: strconv.Itoa(parent)}}, history[1].Changes)
+	}
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_DataVersion_Moves_On_Other_Connections_Commits(t *testing.T) {
+	repo := mustNewRepo(t)
+	other := NewLocalRepository()
+
+	before, err := (*repo).DataVersion()
+	assert.Nil(t, err)
+	_, err = (*repo).CreateTask(data.Task{Title: "Mine"})
+	assert.Nil(t, err)
+	own, err := (*repo).DataVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, before, own, "a repository's own writes do not count")
+
+	_, err = other.CreateTask(data.Task{Title: "Theirs"})
+	assert.Nil(t, err)
+	after, err := (*repo).DataVersion()
+	assert.Nil(t, err)
+	assert.NotEqual(t, before, after)
+
+	t.Cleanup(func() {
+		_ = other.Close()
+		cleanup(repo)
+	})
+}
+
+func Test_UpdateTask_Refuses_Stale_Versions(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	id, err := (*repo).CreateTask(data.Task{Title: "Draft"})
+	assert.Nil(t, err)
+	loaded, err := (*repo).GetTask(id)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, loaded.Version)
+
+	theirs := loaded
+	theirs.Title = "Theirs"
+	assert.Nil(t, (*repo).UpdateTask(theirs))
+	assert.Nil(t, (*repo).UpdateTask(theirs), "an unchanged task is not a conflict")
+
+	mine := loaded
+	mine.Title = "Mine"
+	err = (*repo).UpdateTask(mine)
+	var conflict *ConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, id, conflict.Id)
+		assert.Equal(t, "Theirs", conflict.Current.Title)
+		assert.Equal(t, 2, conflict.Current.Version)
+	}
+
+	mine.Version = conflict.Current.Version
+	assert.Nil(t, (*repo).UpdateTask(mine))
+	stored, err := (*repo).GetTask(id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Mine", stored.Title)
+	assert.Equal(t, 3, stored.Version)
+
+	unchecked := data.Task{Id: id, Title: "Unchecked"}
+	assert.Nil(t, (*repo).UpdateTask(unchecked), "a zero version skips the check")
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_UpdateTasks_Conflict_Changes_Nothing(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	assert.Nil(t, (*repo).SaveTask("T1", time.Time{}))
+	assert.Nil(t, (*repo).SaveTask("T2", time.Time{}))
+	loaded, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+
+	elsewhere := loaded[1]
+	elsewhere.Complete = true
+	assert.Nil(t, (*repo).UpdateTask(elsewhere))
+
+	loaded[0].Title = "T1-updated"
+	loaded[1].Title = "T2-updated"
+	err = (*repo).UpdateTasks(loaded)
+	var conflict *ConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, loaded[1].Id, conflict.Id)
+	}
+
+	after, err := (*repo).GetTasks()
+	assert.Nil(t, err)
+	assert.Equal(t, "T1", after[0].Title, "the batch is rolled back")
+	assert.True(t, after[1].Complete)
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+// Benchmark_UpdateTasks_10k compares saving a 10k task list with one edit by
+// passing every task, as the list view used to on quit, with passing only
+// the edited one.
+func Benchmark_UpdateTasks_10k(b *testing.B) {
+	b.Setenv("TODO_DB", filepath.Join(b.TempDir(), "todo.sqlite"))
+	repo := NewLocalRepository()
+	defer repo.Close()
+
+	seed := make([]data.Task, 10_000)
+	for i := range seed {
+		seed[i] = data.Task{Title: "Task " + strconv.Itoa(i), Project: "bench"}
+	}
+	if _, err := repo.UpsertTasks(seed); err != nil {
+		b.Fatal(err)
+	}
+	tasks, err := repo.GetTasks()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := range tasks {
+		// Each iteration edits the same task, so skip the version check.
+		tasks[i].Version = 0
+	}
+
+	for _, bench := range []struct {
+		name  string
+		tasks func() []data.Task
+	}{
+		{"all", func() []data.Task { return tasks }},
+		{"dirty", func() []data.Task { return tasks[:1] }},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tasks[0].Complete = !tasks[0].Complete
+				if err := repo.UpdateTasks(bench.tasks()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Benchmark_FindTasks_50k compares reading a 50k task list whole with
+// reading its first page and a page from the middle.
+func Benchmark_FindTasks_50k(b *testing.B) {
+	b.Setenv("TODO_DB", filepath.Join(b.TempDir(), "todo.sqlite"))
+	repo := NewLocalRepository()
+	defer repo.Close()
+
+	seed := make([]data.Task, 50_000)
+	day := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	for i := range seed {
+		seed[i] = data.Task{Title: "Task " + strconv.Itoa(i), DueDate: day.AddDate(0, 0, i%90)}
+	}
+	if _, err := repo.UpsertTasks(seed); err != nil {
+		b.Fatal(err)
+	}
+	middle, err := repo.FindTasks(ListOptions{Limit: 25_000})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, bench := range []struct {
+		name string
+		opts ListOptions
+	}{
+		{"all", ListOptions{}},
+		{"first page", ListOptions{Limit: 200}},
+		{"middle page", ListOptions{Limit: 200, After: &middle[len(middle)-1]}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindTasks(bench.opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func Test_Status_And_Complete_Stay_In_Step(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	statuses, err := (*repo).GetStatuses()
+	assert.Nil(t, err)
+	assert.Equal(t, []data.Status{{Name: "todo"}, {Name: "in-progress"}, {Name: "blocked"}, {Name: "done", Terminal: true}}, statuses)
+
+	id, err := (*repo).CreateTask(data.Task{Title: "Ship", Complete: true})
+	assert.Nil(t, err)
+	task, _ := (*repo).GetTask(id)
+	assert.Equal(t, "done", task.Status, "complete tasks start in the first terminal status")
+
+	task.Status = "in-progress"
+	assert.Nil(t, (*repo).UpdateTask(task))
+	task, _ = (*repo).GetTask(id)
+	assert.False(t, task.Complete)
+	assert.True(t, task.CompletedAt.IsZero())
+
+	task.Complete = true
+	assert.Nil(t, (*repo).UpdateTask(task))
+	task, _ = (*repo).GetTask(id)
+	assert.Equal(t, "done", task.Status)
+
+	task.Status = "someday"
+	assert.ErrorContains(t, (*repo).UpdateTask(task), `unknown status "someday"`)
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_SetStatuses(t *testing.T) {
+	repo := mustNewRepo(t)
+
+	id, err := (*repo).CreateTask(data.Task{Title: "Review", Status: "blocked"})
+	assert.Nil(t, err)
+
+	err = (*repo).SetStatuses([]data.Status{{Name: "todo"}, {Name: "done", Terminal: true}})
+	assert.ErrorContains(t, err, `"blocked"`, "tasks are still blocked")
+	assert.Error(t, (*repo).SetStatuses([]data.Status{{Name: "todo"}}), "nothing is terminal")
+
+	workflow := []data.Status{{Name: "todo"}, {Name: "done", Terminal: true}, {Name: "blocked", Terminal: true}}
+	assert.Nil(t, (*repo).SetStatuses(workflow))
+	statuses, err := (*repo).GetStatuses()
+	assert.Nil(t, err)
+	assert.Equal(t, workflow, statuses)
+
+	task, _ := (*repo).GetTask(id)
+	assert.True(t, task.Complete, "tasks in a status that became terminal are completed")
+	assert.Equal(t, "blocked", task.Status)
+	events, _ := (*repo).GetTaskHistory(id)
+	assert.Equal(t, []data.Change{{Field: "complete", Old: "false", New: "true"}}, events[len(events)-1].Changes)
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_LinkTasks_Links_All_Or_Nothing_And_Records_History(t *testing.T) {
+	repo := mustNewRepo(t)
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+	ids, err := (*repo).UpsertTasks([]data.Task{{Title: "Design"}, {Title: "Build"}, {Title: "Ship"}})
+	assert.Nil(t, err)
+	design, build, ship := ids[0], ids[1], ids[2]
+	assert.Nil(t, (*repo).LinkTasks(build, []int{ship}))
+
+	assert.ErrorIs(t, (*repo).LinkTasks(design, []int{build, 99}), ErrTaskNotFound)
+	assert.ErrorContains(t, (*repo).LinkTasks(ship, []int{design, build}), "cycle: 3 → 2 → 3")
+	deps, _ := (*repo).GetDependencies()
+	assert.Equal(t, []data.Dependency{{Blocker: build, Blocked: ship}}, deps, "failed calls link nothing")
+
+	assert.ErrorContains(t, (*repo).LinkTasks(design, []int{build, ship, design}), "cannot block itself")
+	assert.Nil(t, (*repo).LinkTasks(design, []int{build, ship}))
+	assert.Nil(t, (*repo).UnlinkTasks(design, []int{ship, 99}))
+	deps, _ = (*repo).GetDependencies()
+	assert.Equal(t, []data.Dependency{{Blocker: design, Blocked: build}, {Blocker: build, Blocked: ship}}, deps)
+
+	events, _ := (*repo).GetTaskHistory(design)
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, []data.Change{{Field: "blocks", New: "2"}}, events[1].Changes)
+		assert.Equal(t, []data.Change{{Field: "blocks", New: "3"}}, events[2].Changes)
+		assert.Equal(t, []data.Change{{Field: "blocks", Old: "3"}}, events[3].Changes)
+	}
+	events, _ = (*repo).GetTaskHistory(ship)
+	if assert.Len(t, events, 4) {
+		assert.Equal(t, []data.Change{{Field: "blocked by", New: "2"}}, events[1].Changes)
+		assert.Equal(t, []data.Change{{Field: "blocked by", Old: "1"}}, events[3].Changes)
+	}
+}
+
+func Test_SetStatuses_Refuses_To_Complete_Blocked_Tasks(t *testing.T) {
+	repo := mustNewRepo(t)
+	ids, err := (*repo).UpsertTasks([]data.Task{{Title: "Design"}, {Title: "Build", Status: "blocked"}})
+	assert.Nil(t, err)
+	assert.Nil(t, (*repo).LinkTasks(ids[0], []int{ids[1]}))
+
+	workflow := []data.Status{{Name: "todo"}, {Name: "blocked", Terminal: true}, {Name: "done", Terminal: true}}
+	var blocked *BlockedError
+	if assert.ErrorAs(t, (*repo).SetStatuses(workflow), &blocked) {
+		assert.Equal(t, &BlockedError{Id: ids[1], Blockers: []int{ids[0]}}, blocked)
+	}
+	task, _ := (*repo).GetTask(ids[1])
+	assert.False(t, task.Complete)
+	statuses, _ := (*repo).GetStatuses()
+	assert.Len(t, statuses, 4, "the workflow was not changed")
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
+
+func Test_Dependencies(t *testing.T) {
+	repo := mustNewRepo(t)
+	ids, err := (*repo).UpsertTasks([]data.Task{{Title: "Design"}, {Title: "Build"}, {Title: "Ship"}})
+	assert.Nil(t, err)
+	design, build, ship := ids[0], ids[1], ids[2]
+
+	assert.Nil(t, (*repo).LinkTasks(design, []int{build}))
+	assert.Nil(t, (*repo).LinkTasks(build, []int{ship}))
+	assert.Nil(t, (*repo).LinkTasks(build, []int{ship}), "linking twice is harmless")
+	assert.ErrorContains(t, (*repo).LinkTasks(ship, []int{design}), "cycle: 3 → 1 → 2 → 3")
+	assert.ErrorContains(t, (*repo).LinkTasks(ship, []int{ship}), "cannot block itself")
+	assert.ErrorIs(t, (*repo).LinkTasks(ship, []int{99}), ErrTaskNotFound)
+
+	deps, err := (*repo).GetDependencies()
+	assert.Nil(t, err)
+	assert.Equal(t, []data.Dependency{{Blocker: design, Blocked: build}, {Blocker: build, Blocked: ship}}, deps)
+
+	task, _ := (*repo).GetTask(build)
+	task.Complete = true
+	var blocked *BlockedError
+	if assert.ErrorAs(t, (*repo).UpdateTask(task), &blocked) {
+		assert.Equal(t, &BlockedError{Id: build, Blockers: []int{design}}, blocked)
+	}
+	task, _ = (*repo).GetTask(build)
+	assert.False(t, task.Complete, "nothing was stored")
+
+	first, _ := (*repo).GetTask(design)
+	first.Complete = true
+	task.Complete = true
+	assert.Nil(t, (*repo).UpdateTasks([]data.Task{task, first}), "a blocker completed in the same batch no longer blocks")
+
+	assert.Nil(t, (*repo).UnlinkTasks(build, []int{ship}))
+	assert.Nil(t, (*repo).LinkTasks(ship, []int{design}))
+	assert.Nil(t, (*repo).DeleteTaskById(ship))
+	deps, _ = (*repo).GetDependencies()
+	assert.Equal(t, []data.Dependency{{Blocker: design, Blocked: build, BlockerComplete: true, BlockedComplete: true}}, deps,
+		"deleting a task removes its links")
+	events, _ := (*repo).GetTaskHistory(design)
+	assert.Equal(t, []data.Change{{Field: "blocked by", Old: strconv.Itoa(ship)}}, events[len(events)-1].Changes,
+		"the surviving task's history says why its blocker went")
+	events, _ = (*repo).GetTaskHistory(ship)
+	if assert.GreaterOrEqual(t, len(events), 2) {
+		assert.Equal(t, []data.Change{{Field: "blocks", Old: strconv.Itoa(design)}}, events[len(events)-2].Changes)
+		assert.Equal(t, data.EventDeleted, events[len(events)-1].Kind)
+	}
+
+	t.Cleanup(func() {
+		cleanup(repo)
+	})
+}
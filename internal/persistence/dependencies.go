@@ -0,0 +1,205 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+// BlockedError is returned when an update would complete a task that still
+// waits on open tasks, listed in Blockers.
+type BlockedError struct {
+	Id       int
+	Blockers []int
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("task %d is blocked by open task(s) %s", e.Id, data.JoinIds(e.Blockers))
+}
+
+const selectDependencies = `
+SELECT d.blocker_id, d.blocked_id, blocker.complete, blocked.complete
+FROM task_dependencies d
+JOIN tasks blocker ON blocker.id = d.blocker_id
+JOIN tasks blocked ON blocked.id = d.blocked_id
+ORDER BY d.blocker_id, d.blocked_id`
+
+func (t *SqlLiteTodoRepository) GetDependencies() ([]data.Dependency, error) {
+	ctx := context.TODO()
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return getDependenciesTx(ctx, tx)
+}
+
+func getDependenciesTx(ctx context.Context, tx *sql.Tx) (data.Dependencies, error) {
+	rows, err := tx.QueryContext(ctx, selectDependencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deps data.Dependencies
+	for rows.Next() {
+		var dep data.Dependency
+		if err := rows.Scan(&dep.Blocker, &dep.Blocked, &dep.BlockerComplete, &dep.BlockedComplete); err != nil {
+			return deps, err
+		}
+		deps = append(deps, dep)
+	}
+	return deps, rows.Err()
+}
+
+func (t *SqlLiteTodoRepository) LinkTasks(blocker int, blocked []int) (err error) {
+	ctx := context.TODO()
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, id := range append([]int{blocker}, blocked...) {
+		if _, err = getTaskTx(ctx, tx, id); errors.Is(err, sql.ErrNoRows) {
+			err = ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	deps, err := getDependenciesTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, id := range blocked {
+		if id == blocker {
+			err = fmt.Errorf("task %d cannot block itself", blocker)
+			return err
+		}
+		// Links made earlier in this call count towards cycles too.
+		if path := deps.Path(id, blocker); path != nil {
+			cycle := append([]int{blocker}, path...)
+			err = fmt.Errorf("task %d already waits on task %d; linking them would make a cycle: %s", blocker, id, data.JoinPath(cycle))
+			return err
+		}
+		var result sql.Result
+		if result, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO task_dependencies (blocker_id, blocked_id) VALUES (?, ?)`, blocker, id); err != nil {
+			return err
+		}
+		if err = t.recordLinkTx(ctx, tx, result, blocker, id, true); err != nil {
+			return err
+		}
+		deps = append(deps, data.Dependency{Blocker: blocker, Blocked: id})
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (t *SqlLiteTodoRepository) UnlinkTasks(blocker int, blocked []int) (err error) {
+	ctx := context.TODO()
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, id := range blocked {
+		if err = t.unlinkTx(ctx, tx, blocker, id); err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+// unlinkTx removes the link from blocker to blocked, if there is one, and
+// records the removal.
+func (t *SqlLiteTodoRepository) unlinkTx(ctx context.Context, tx *sql.Tx, blocker int, blocked int) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM task_dependencies WHERE blocker_id = ? AND blocked_id = ?`, blocker, blocked)
+	if err != nil {
+		return err
+	}
+	return t.recordLinkTx(ctx, tx, result, blocker, blocked, false)
+}
+
+// unlinkTaskTx removes every link to or from id, recording each removal in
+// the history of the tasks on both ends.
+func (t *SqlLiteTodoRepository) unlinkTaskTx(ctx context.Context, tx *sql.Tx, id int) error {
+	rows, err := tx.QueryContext(ctx, `SELECT blocker_id, blocked_id FROM task_dependencies WHERE blocker_id = ? OR blocked_id = ? ORDER BY blocker_id, blocked_id`, id, id)
+	if err != nil {
+		return err
+	}
+	var links []data.Dependency
+	for rows.Next() {
+		var link data.Dependency
+		if err := rows.Scan(&link.Blocker, &link.Blocked); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		links = append(links, link)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	for _, link := range links {
+		if err := t.unlinkTx(ctx, tx, link.Blocker, link.Blocked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordLinkTx adds the link between blocker and blocked, or its removal,
+// to both tasks' history when result shows the statement changed it.
+func (t *SqlLiteTodoRepository) recordLinkTx(ctx context.Context, tx *sql.Tx, result sql.Result, blocker int, blocked int, linked bool) error {
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	change := func(field string, id int) []data.Change {
+		if linked {
+			return []data.Change{{Field: field, New: strconv.Itoa(id)}}
+		}
+		return []data.Change{{Field: field, Old: strconv.Itoa(id)}}
+	}
+	if err := t.recordEvent(ctx, tx, blocker, data.EventUpdated, change("blocks", blocked)); err != nil {
+		return err
+	}
+	return t.recordEvent(ctx, tx, blocked, data.EventUpdated, change("blocked by", blocker))
+}
+
+const selectOpenBlockers = `
+SELECT d.blocker_id FROM task_dependencies d JOIN tasks t ON t.id = d.blocker_id
+WHERE d.blocked_id = ? AND NOT t.complete
+ORDER BY d.blocker_id`
+
+// openBlockersTx returns the ids of the open tasks id waits on.
+func openBlockersTx(ctx context.Context, tx *sql.Tx, id int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, selectOpenBlockers, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var blocker int
+		if err := rows.Scan(&blocker); err != nil {
+			return ids, err
+		}
+		ids = append(ids, blocker)
+	}
+	return ids, rows.Err()
+}
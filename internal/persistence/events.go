@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+const insertEvent = `INSERT INTO task_events (task_id, kind, at, changes) VALUES (?, ?, ?, ?)`
+
+// recordEvent appends to the task_events log. It must be called with the
+// transaction that made the change so history and data never disagree.
+func (t *SqlLiteTodoRepository) recordEvent(ctx context.Context, tx *sql.Tx, taskId int, kind data.EventKind, changes []data.Change) error {
+	encoded, err := encodeChanges(changes)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertEvent, taskId, string(kind), t.now().UTC().Unix(), encoded)
+	return err
+}
+
+func encodeChanges(changes []data.Change) (string, error) {
+	if changes == nil {
+		changes = []data.Change{}
+	}
+	encoded, err := json.Marshal(changes)
+	return string(encoded), err
+}
+
+// GetTaskHistory returns every recorded event for a task, oldest first. It
+// keeps working after the task itself has been deleted.
+func (t *SqlLiteTodoRepository) GetTaskHistory(id int) ([]data.Event, error) {
+	ctx := context.TODO()
+	rows, err := t.db.QueryContext(ctx, `SELECT id, task_id, kind, at, changes FROM task_events WHERE task_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []data.Event
+	for rows.Next() {
+		var event data.Event
+		var kind, changes string
+		var at time.Time
+		if err := rows.Scan(&event.Id, &event.TaskId, &kind, &at, &changes); err != nil {
+			return events, err
+		}
+		event.Kind = data.EventKind(kind)
+		event.At = at
+		if err := json.Unmarshal([]byte(changes), &event.Changes); err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func getTaskTx(ctx context.Context, tx *sql.Tx, id int) (data.Task, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return data.Task{}, err
+	}
+	if len(tasks) == 0 {
+		return data.Task{}, sql.ErrNoRows
+	}
+	return tasks[0], nil
+}
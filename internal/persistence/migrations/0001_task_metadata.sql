@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN priority TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN project TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN notes TEXT NOT NULL DEFAULT '';
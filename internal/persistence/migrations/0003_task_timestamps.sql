@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN created_at DATETIME;
+ALTER TABLE tasks ADD COLUMN updated_at DATETIME;
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+UPDATE tasks SET created_at = unixepoch(), updated_at = unixepoch();
@@ -0,0 +1,16 @@
+CREATE TABLE task_events (
+    id INTEGER PRIMARY KEY NOT NULL,
+    task_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    at DATETIME NOT NULL,
+    changes TEXT NOT NULL DEFAULT '[]'
+);
+CREATE INDEX task_events_by_task ON task_events (task_id, id);
+CREATE TRIGGER task_events_append_only_update BEFORE UPDATE ON task_events
+BEGIN
+    SELECT RAISE(ABORT, 'task_events is append-only');
+END;
+CREATE TRIGGER task_events_append_only_delete BEFORE DELETE ON task_events
+BEGIN
+    SELECT RAISE(ABORT, 'task_events is append-only');
+END;
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX tasks_by_uid ON tasks (uid) WHERE uid != '';
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX tasks_by_parent ON tasks (parent_id) WHERE parent_id != 0;
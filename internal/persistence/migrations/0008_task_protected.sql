@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN protected INTEGER NOT NULL DEFAULT 0;
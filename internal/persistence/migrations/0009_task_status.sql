@@ -0,0 +1,12 @@
+CREATE TABLE statuses (
+    name TEXT PRIMARY KEY NOT NULL,
+    position INTEGER NOT NULL,
+    terminal INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO statuses (name, position, terminal) VALUES
+    ('todo', 0, 0),
+    ('in-progress', 1, 0),
+    ('blocked', 2, 0),
+    ('done', 3, 1);
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo';
+UPDATE tasks SET status = 'done' WHERE complete = 1;
@@ -0,0 +1,6 @@
+CREATE TABLE task_dependencies (
+    blocker_id INTEGER NOT NULL,
+    blocked_id INTEGER NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+CREATE INDEX task_dependencies_by_blocked ON task_dependencies (blocked_id);
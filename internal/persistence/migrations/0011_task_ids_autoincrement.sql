@@ -0,0 +1,31 @@
+CREATE TABLE tasks_autoincrement (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    title TEXT NOT NULL,
+    due_date DATE NOT NULL,
+    complete BOOLEAN NOT NULL DEFAULT FALSE,
+    priority TEXT NOT NULL DEFAULT '',
+    project TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME,
+    updated_at DATETIME,
+    completed_at DATETIME,
+    uid TEXT NOT NULL DEFAULT '',
+    parent_id INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    protected INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'todo'
+);
+INSERT INTO tasks_autoincrement (id, title, due_date, complete, priority, project, tags, notes, created_at, updated_at,
+                                 completed_at, uid, parent_id, version, protected, status)
+SELECT id, title, due_date, complete, priority, project, tags, notes, created_at, updated_at,
+       completed_at, uid, parent_id, version, protected, status
+FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_autoincrement RENAME TO tasks;
+CREATE UNIQUE INDEX tasks_by_uid ON tasks (uid) WHERE uid != '';
+CREATE INDEX tasks_by_parent ON tasks (parent_id) WHERE parent_id != 0;
+-- Deleted tasks keep their history, so new ids start past every id it names.
+DELETE FROM sqlite_sequence WHERE name IN ('tasks', 'tasks_autoincrement');
+INSERT INTO sqlite_sequence (name, seq)
+SELECT 'tasks', max(coalesce((SELECT max(id) FROM tasks), 0), coalesce((SELECT max(task_id) FROM task_events), 0));
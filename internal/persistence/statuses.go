@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+func (t *SqlLiteTodoRepository) GetStatuses() ([]data.Status, error) {
+	ctx := context.TODO()
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return getStatusesTx(ctx, tx)
+}
+
+func getStatusesTx(ctx context.Context, tx *sql.Tx) (data.Statuses, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name, terminal FROM statuses ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var statuses data.Statuses
+	for rows.Next() {
+		var status data.Status
+		if err := rows.Scan(&status.Name, &status.Terminal); err != nil {
+			return statuses, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, rows.Err()
+}
+
+// SetStatuses replaces the workflow in one transaction. Tasks keep their
+// status, but those in a status whose Terminal flag changed are completed or
+// reopened to match, with the change recorded in their history.
+func (t *SqlLiteTodoRepository) SetStatuses(statuses []data.Status) (err error) {
+	if err := data.Statuses(statuses).Validate(); err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT status FROM tasks ORDER BY status`)
+	if err != nil {
+		return err
+	}
+	var used []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		used = append(used, name)
+	}
+	if err = rows.Close(); err != nil {
+		return err
+	}
+	for _, name := range used {
+		if _, ok := data.Statuses(statuses).Find(name); !ok {
+			err = fmt.Errorf("tasks are still in status %q; move them first", name)
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM statuses`); err != nil {
+		return err
+	}
+	for i, status := range statuses {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO statuses (name, position, terminal) VALUES (?, ?, ?)`, status.Name, i, status.Terminal); err != nil {
+			return err
+		}
+	}
+	if err = t.completeByStatusTx(ctx, tx, statuses); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+// completeByStatusTx completes or reopens the tasks whose Complete no longer
+// matches whether their status is terminal. Completing a task that waits on
+// an open one is a *BlockedError, as it is from UpdateTasks.
+func (t *SqlLiteTodoRepository) completeByStatusTx(ctx context.Context, tx *sql.Tx, statuses data.Statuses) error {
+	rows, err := tx.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE complete != (SELECT terminal FROM statuses WHERE name = tasks.status) ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return err
+	}
+	w, err := t.prepareWriter(ctx, tx)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	for _, old := range tasks {
+		status, _ := statuses.Find(old.Status)
+		task := old
+		task.Complete = status.Terminal
+		if err := w.store(ctx, old, task); err != nil {
+			return err
+		}
+	}
+	return w.checkBlocked(ctx)
+}
@@ -0,0 +1,367 @@
+// Package taskwarrior reads and writes tasks in Taskwarrior's JSON export
+// format, as produced by `task export` and accepted by `task import`.
+//
+// uuid, description, status, entry, end, due, project, priority, tags and
+// annotations are mapped onto tasks. Taskwarrior's H, M and L priorities
+// become A, B and C; D and below are exported as L. Annotations are joined
+// into the notes. Native tasks are exported with a UUID derived from their Id
+// and a gotodoid attribute, which Taskwarrior keeps as an orphaned UDA, so a
+// round trip updates the original tasks. Tasks imported from elsewhere keep
+// their UUID so re-imports update them.
+package taskwarrior
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+const (
+	timeLayout = "20060102T150405Z"
+	idField    = "gotodoid"
+)
+
+type record struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       string       `json:"entry,omitempty"`
+	Modified    string       `json:"modified,omitempty"`
+	End         string       `json:"end,omitempty"`
+	Due         string       `json:"due,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Annotations []annotation `json:"annotations,omitempty"`
+	GoTodoId    todoId       `json:"gotodoid,omitempty"`
+}
+
+// todoId is the gotodoid attribute. Taskwarrior stores attributes it has no
+// definition for as strings, so either a number or a string is accepted.
+type todoId int
+
+func (id *todoId) UnmarshalJSON(b []byte) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		*id = todoId(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid %s %s", idField, b)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", idField, s)
+	}
+	*id = todoId(n)
+	return nil
+}
+
+type annotation struct {
+	Entry       string `json:"entry,omitempty"`
+	Description string `json:"description"`
+}
+
+// mapped lists the attributes Parse understands. id, urgency and modified
+// are computed by Taskwarrior and deliberately ignored.
+var mapped = map[string]bool{
+	"uuid": true, "description": true, "status": true, "entry": true, "end": true,
+	"due": true, "project": true, "priority": true, "tags": true, "annotations": true,
+	idField: true, "id": true, "urgency": true, "modified": true,
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// namespace is the RFC 4122 URL namespace, used to derive UUIDs.
+var namespace = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// UUID returns the Taskwarrior UUID a task is exported with: its own UID when
+// that is already a UUID, otherwise a name-based (version 5) UUID derived
+// from its UID or Id.
+func UUID(task data.Task) string {
+	if uuid := strings.ToLower(task.UID); uuidPattern.MatchString(uuid) {
+		return uuid
+	}
+	if task.UID != "" {
+		return nameUUID(task.UID)
+	}
+	return nativeUUID(task.Id)
+}
+
+func nativeUUID(id int) string {
+	return nameUUID(fmt.Sprintf("go-todo-cli:task:%d", id))
+}
+
+func nameUUID(name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	sum := h.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// Write writes tasks as a JSON array with one task per line, like
+// `task export`. Due dates are written as midnight in loc.
+func Write(w io.Writer, tasks []data.Task, loc *time.Location) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[\n")
+	for i, task := range tasks {
+		line, err := json.Marshal(toRecord(task, loc))
+		if err != nil {
+			return err
+		}
+		bw.Write(line)
+		if i < len(tasks)-1 {
+			bw.WriteString(",")
+		}
+		bw.WriteString("\n")
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+func toRecord(task data.Task, loc *time.Location) record {
+	r := record{
+		UUID:        UUID(task),
+		Description: task.Title,
+		Status:      "pending",
+		Entry:       formatTime(task.CreatedAt),
+		Modified:    formatTime(task.UpdatedAt),
+		Project:     task.Project,
+		Priority:    twPriority(task.Priority),
+		Tags:        task.Tags,
+		GoTodoId:    todoId(task.Id),
+	}
+	if task.Complete {
+		r.Status = "completed"
+		r.End = formatTime(task.CompletedAt)
+	}
+	if !task.DueDate.IsZero() {
+		d := task.DueDate
+		r.Due = formatTime(time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc))
+	}
+	if task.Notes != "" {
+		r.Annotations = []annotation{{Entry: r.Entry, Description: task.Notes}}
+	}
+	return r
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+// Report describes what an import left behind: attributes with no
+// equivalent, and tasks skipped by status, each with how often they
+// occurred.
+type Report struct {
+	Unmapped map[string]int
+	Skipped  map[string]int
+}
+
+// Write prints one line per skipped status and a line listing unmapped
+// attributes, or nothing when everything was imported.
+func (r Report) Write(w io.Writer) error {
+	for _, status := range sortedKeys(r.Skipped) {
+		if _, err := fmt.Fprintf(w, "skipped %d %s Taskwarrior task(s)\n", r.Skipped[status], status); err != nil {
+			return err
+		}
+	}
+	if len(r.Unmapped) == 0 {
+		return nil
+	}
+	var parts []string
+	for _, name := range sortedKeys(r.Unmapped) {
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, r.Unmapped[name]))
+	}
+	_, err := fmt.Fprintf(w, "ignored unmapped Taskwarrior attributes: %s\n", strings.Join(parts, ", "))
+	return err
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Parse reads a Taskwarrior export: a JSON array, or one JSON object per
+// line as older versions wrote. Deleted tasks and recurring templates are
+// skipped; waiting tasks are imported as open. Due dates are read as a
+// calendar day in loc.
+func Parse(r io.Reader, loc *time.Location) ([]data.Task, Report, error) {
+	report := Report{Unmapped: map[string]int{}, Skipped: map[string]int{}}
+	objects, err := decodeObjects(r)
+	if err != nil {
+		return nil, report, err
+	}
+
+	var tasks []data.Task
+	for i, object := range objects {
+		var rec record
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(object, &rec); err != nil {
+			return nil, report, fmt.Errorf("task %d: %w", i+1, err)
+		}
+		if err := json.Unmarshal(object, &attributes); err != nil {
+			return nil, report, fmt.Errorf("task %d: %w", i+1, err)
+		}
+		switch rec.Status {
+		case "deleted", "recurring":
+			report.Skipped[rec.Status]++
+			continue
+		}
+		for name := range attributes {
+			if !mapped[name] {
+				report.Unmapped[name]++
+			}
+		}
+		task, err := fromRecord(rec, loc)
+		if err != nil {
+			return nil, report, fmt.Errorf("task %d: %w", i+1, err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, report, nil
+}
+
+func decodeObjects(r io.Reader) ([]json.RawMessage, error) {
+	br := bufio.NewReader(r)
+	first, err := firstNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		var objects []json.RawMessage
+		err := dec.Decode(&objects)
+		return objects, err
+	}
+	var objects []json.RawMessage
+	for {
+		var object json.RawMessage
+		err := dec.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+}
+
+// firstNonSpace peeks at the first significant byte without consuming it.
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+func fromRecord(rec record, loc *time.Location) (data.Task, error) {
+	if rec.Description == "" {
+		return data.Task{}, fmt.Errorf("missing description")
+	}
+	task := data.Task{
+		Title:    rec.Description,
+		Complete: rec.Status == "completed",
+		Project:  rec.Project,
+		Priority: priorityLetter(rec.Priority),
+		Tags:     rec.Tags,
+		UID:      strings.ToLower(rec.UUID),
+	}
+	if rec.GoTodoId > 0 {
+		task.Id = int(rec.GoTodoId)
+		if task.UID == nativeUUID(task.Id) {
+			task.UID = ""
+		}
+	}
+
+	var err error
+	if task.CreatedAt, err = parseTime("entry", rec.Entry); err != nil {
+		return task, err
+	}
+	if task.Complete {
+		if task.CompletedAt, err = parseTime("end", rec.End); err != nil {
+			return task, err
+		}
+	}
+	due, err := parseTime("due", rec.Due)
+	if err != nil {
+		return task, err
+	}
+	if !due.IsZero() {
+		task.DueDate = data.CalendarDay(due.In(loc), time.UTC)
+	}
+
+	notes := make([]string, 0, len(rec.Annotations))
+	for _, a := range rec.Annotations {
+		notes = append(notes, a.Description)
+	}
+	task.Notes = strings.Join(notes, "\n")
+	return task, nil
+}
+
+func parseTime(name string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return t, nil
+}
+
+func twPriority(p string) string {
+	switch {
+	case p == "":
+		return ""
+	case p == "A":
+		return "H"
+	case p == "B":
+		return "M"
+	default:
+		return "L"
+	}
+}
+
+func priorityLetter(p string) string {
+	switch p {
+	case "H":
+		return "A"
+	case "M":
+		return "B"
+	case "L":
+		return "C"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,149 @@
+package taskwarrior
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// berlin is east of Greenwich, so local midnights fall on the previous UTC day.
+var berlin = time.FixedZone("CEST", 2*60*60)
+
+func fixtureTasks() []data.Task {
+	return []data.Task{
+		{
+			Id:        1,
+			Title:     "Write \"Q4\" report",
+			DueDate:   time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC),
+			Priority:  "A",
+			Project:   "work",
+			Tags:      []string{"docs", "q4"},
+			Notes:     "Use the Q3 template.",
+			CreatedAt: time.Date(2025, time.September, 28, 8, 30, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2025, time.September, 29, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			Id:          2,
+			Title:       "File expenses",
+			Complete:    true,
+			Priority:    "D",
+			CreatedAt:   time.Date(2025, time.September, 20, 8, 0, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2025, time.September, 30, 12, 0, 0, 0, time.UTC),
+			CompletedAt: time.Date(2025, time.September, 30, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			Id:    3,
+			Title: "Imported from Taskwarrior",
+			UID:   "5f6e7d8c-9b0a-4c1d-8e2f-3a4b5c6d7e8f",
+		},
+		{
+			Id:    4,
+			Title: "Imported from a calendar",
+			UID:   "040000008200E00074C5B7101A82E008@example.com",
+		},
+	}
+}
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWrite_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), berlin))
+	golden(t, "export.json", buf.Bytes())
+}
+
+func TestParse_RoundTripsExport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, fixtureTasks(), berlin))
+
+	got, report, err := Parse(&buf, berlin)
+	require.NoError(t, err)
+	assert.Empty(t, report.Unmapped)
+	assert.Empty(t, report.Skipped)
+
+	want := fixtureTasks()
+	for i := range want {
+		// modified is owned by the repository and not imported.
+		want[i].UpdatedAt = time.Time{}
+	}
+	// Priorities below C collapse onto Taskwarrior's L.
+	want[1].Priority = "C"
+	// A UID that is not a UUID is exported as one derived from it.
+	want[3].UID = UUID(want[3])
+	assert.Equal(t, want, got)
+}
+
+func TestParse_TaskwarriorExport(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "import.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, report, err := Parse(f, berlin)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"deleted": 1, "recurring": 1}, report.Skipped)
+	assert.Equal(t, map[string]int{"depends": 1, "estimate": 1, "wait": 1}, report.Unmapped)
+
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, "8a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", got[0].UID)
+		assert.Equal(t, 0, got[0].Id, "Taskwarrior's working-set id is not a task id")
+		assert.Equal(t, time.Date(2025, time.October, 7, 0, 0, 0, 0, time.UTC), got[0].DueDate, "due is a local midnight")
+		assert.Equal(t, "A", got[0].Priority)
+		assert.Equal(t, "home.repairs", got[0].Project)
+		assert.Equal(t, []string{"money", "house"}, got[0].Tags)
+		assert.Equal(t, "Invoice is in the drawer\nCall first", got[0].Notes)
+		assert.Equal(t, time.Date(2025, time.September, 28, 8, 15, 0, 0, time.UTC), got[0].CreatedAt)
+
+		assert.True(t, got[1].Complete)
+		assert.Equal(t, time.Date(2025, time.October, 2, 10, 0, 0, 0, time.UTC), got[1].CompletedAt)
+
+		assert.False(t, got[2].Complete, "waiting tasks are open")
+		assert.Equal(t, 12, got[2].Id, "gotodoid maps back to the task id, even as a string")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	assert.Equal(t, `skipped 1 deleted Taskwarrior task(s)
+skipped 1 recurring Taskwarrior task(s)
+ignored unmapped Taskwarrior attributes: depends (1), estimate (1), wait (1)
+`, buf.String())
+}
+
+func TestParse_AcceptsOneObjectPerLine(t *testing.T) {
+	got, _, err := Parse(strings.NewReader(`{"uuid":"a","description":"One","status":"pending"}
+{"uuid":"b","description":"Two","status":"pending"}
+`), time.UTC)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestParse_RequiresDescription(t *testing.T) {
+	_, _, err := Parse(strings.NewReader(`[{"uuid":"a","status":"pending"}]`), time.UTC)
+	assert.ErrorContains(t, err, "task 1: missing description")
+}
+
+func TestUUID(t *testing.T) {
+	native := UUID(data.Task{Id: 3})
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, native)
+	assert.Equal(t, native, UUID(data.Task{Id: 3}), "derived UUIDs are stable")
+	assert.NotEqual(t, native, UUID(data.Task{Id: 4}))
+	assert.Equal(t, "5f6e7d8c-9b0a-4c1d-8e2f-3a4b5c6d7e8f", UUID(data.Task{Id: 3, UID: "5F6E7D8C-9B0A-4C1D-8E2F-3A4B5C6D7E8F"}))
+}
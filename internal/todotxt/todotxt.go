@@ -0,0 +1,170 @@
+// Package todotxt reads and writes tasks in the todo.txt format
+// (https://github.com/todotxt/todo.txt).
+//
+// Mapping onto data.Task:
+//
+//	x 2025-10-02 2025-09-30 (A) Title +project @tag due:2025-10-05 id:3
+//
+// The completion marker and dates map to Complete, CompletedAt and
+// CreatedAt; (A) to Priority (written as pri:A on completed tasks, as is
+// conventional); the first +project to Project, with further projects left in
+// the title; every @context to Tags; due: to DueDate; and id: to Id so that
+// a re-import updates the tasks it came from. Other key:value pairs stay in
+// the title. Notes have no todo.txt equivalent and are not exported.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+)
+
+// Write writes one line per task.
+func Write(w io.Writer, tasks []data.Task) error {
+	for _, task := range tasks {
+		if _, err := fmt.Fprintln(w, Format(task)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format renders a single task as a todo.txt line.
+func Format(task data.Task) string {
+	var parts []string
+	if task.Complete {
+		parts = append(parts, "x")
+		if !task.CompletedAt.IsZero() {
+			parts = append(parts, task.CompletedAt.Local().Format(time.DateOnly))
+			if !task.CreatedAt.IsZero() {
+				parts = append(parts, task.CreatedAt.Local().Format(time.DateOnly))
+			}
+		}
+	} else {
+		if task.Priority != "" {
+			parts = append(parts, "("+task.Priority+")")
+		}
+		if !task.CreatedAt.IsZero() {
+			parts = append(parts, task.CreatedAt.Local().Format(time.DateOnly))
+		}
+	}
+
+	parts = append(parts, task.Title)
+	if task.Project != "" {
+		parts = append(parts, "+"+task.Project)
+	}
+	for _, tag := range task.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	if !task.DueDate.IsZero() {
+		parts = append(parts, "due:"+task.DueDate.Format(time.DateOnly))
+	}
+	if task.Complete && task.Priority != "" {
+		parts = append(parts, "pri:"+task.Priority)
+	}
+	if task.Id != 0 {
+		parts = append(parts, "id:"+strconv.Itoa(task.Id))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Parse reads every non-blank line of r as a task. Errors name the
+// offending line number.
+func Parse(r io.Reader) ([]data.Task, error) {
+	var tasks []data.Task
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		task, err := ParseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, scanner.Err()
+}
+
+// ParseLine parses a single todo.txt line.
+func ParseLine(line string) (data.Task, error) {
+	var task data.Task
+	tokens := strings.Fields(line)
+
+	if len(tokens) > 0 && tokens[0] == "x" {
+		task.Complete = true
+		tokens = tokens[1:]
+		if d, ok := parseDate(tokens); ok {
+			task.CompletedAt = d
+			tokens = tokens[1:]
+			if d, ok := parseDate(tokens); ok {
+				task.CreatedAt = d
+				tokens = tokens[1:]
+			}
+		}
+	} else {
+		if len(tokens) > 0 && isPriority(tokens[0]) {
+			task.Priority = tokens[0][1:2]
+			tokens = tokens[1:]
+		}
+		if d, ok := parseDate(tokens); ok {
+			task.CreatedAt = d
+			tokens = tokens[1:]
+		}
+	}
+
+	var title []string
+	for _, token := range tokens {
+		switch {
+		case len(token) > 1 && token[0] == '+' && task.Project == "":
+			task.Project = token[1:]
+		case len(token) > 1 && token[0] == '@':
+			task.Tags = append(task.Tags, token[1:])
+		case strings.HasPrefix(token, "due:"):
+			due, err := time.Parse(time.DateOnly, strings.TrimPrefix(token, "due:"))
+			if err != nil {
+				return task, fmt.Errorf("invalid due date %q", token)
+			}
+			task.DueDate = due
+		case strings.HasPrefix(token, "pri:"):
+			priority, err := data.ParsePriority(strings.TrimPrefix(token, "pri:"))
+			if err != nil {
+				return task, err
+			}
+			task.Priority = priority
+		case strings.HasPrefix(token, "id:"):
+			id, err := strconv.Atoi(strings.TrimPrefix(token, "id:"))
+			if err != nil {
+				return task, fmt.Errorf("invalid id %q", token)
+			}
+			task.Id = id
+		default:
+			title = append(title, token)
+		}
+	}
+	task.Title = strings.Join(title, " ")
+	if task.Title == "" {
+		return task, fmt.Errorf("task has no description")
+	}
+	return task, nil
+}
+
+func isPriority(token string) bool {
+	return len(token) == 3 && token[0] == '(' && token[2] == ')' && token[1] >= 'A' && token[1] <= 'Z'
+}
+
+func parseDate(tokens []string) (time.Time, bool) {
+	if len(tokens) == 0 {
+		return time.Time{}, false
+	}
+	d, err := time.ParseInLocation(time.DateOnly, tokens[0], time.Local)
+	return d, err == nil
+}
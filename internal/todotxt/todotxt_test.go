@@ -0,0 +1,103 @@
+package todotxt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func localDay(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParseLine_FullIncompleteTask(t *testing.T) {
+	task, err := ParseLine("(A) 2025-09-30 Call mum +family @phone @home due:2025-10-05 id:7")
+	assert.NoError(t, err)
+	assert.Equal(t, data.Task{
+		Id:        7,
+		Title:     "Call mum",
+		Priority:  "A",
+		Project:   "family",
+		Tags:      []string{"phone", "home"},
+		DueDate:   time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC),
+		CreatedAt: localDay(2025, time.September, 30),
+	}, task)
+}
+
+func TestParseLine_CompletedTask(t *testing.T) {
+	task, err := ParseLine("x 2025-10-02 2025-09-30 File taxes pri:B")
+	assert.NoError(t, err)
+	assert.True(t, task.Complete)
+	assert.Equal(t, "B", task.Priority)
+	assert.Equal(t, localDay(2025, time.October, 2), task.CompletedAt)
+	assert.Equal(t, localDay(2025, time.September, 30), task.CreatedAt)
+	assert.Equal(t, "File taxes", task.Title)
+}
+
+func TestParseLine_KeepsUnknownTokensInTitle(t *testing.T) {
+	task, err := ParseLine("Read https://example.com +one +two rec:1w")
+	assert.NoError(t, err)
+	assert.Equal(t, "Read https://example.com +two rec:1w", task.Title)
+	assert.Equal(t, "one", task.Project)
+	assert.True(t, task.DueDate.IsZero())
+}
+
+func TestParseLine_NotAPriorityOrCompletion(t *testing.T) {
+	task, err := ParseLine("xylophone lessons (a)")
+	assert.NoError(t, err)
+	assert.False(t, task.Complete)
+	assert.Equal(t, "", task.Priority)
+	assert.Equal(t, "xylophone lessons (a)", task.Title)
+}
+
+func TestParse_ReportsLineNumbers(t *testing.T) {
+	_, err := Parse(strings.NewReader("ok task\n\nbad due:tomorrow\n"))
+	assert.ErrorContains(t, err, "line 3")
+
+	_, err = Parse(strings.NewReader("(A) +project @ctx\n"))
+	assert.ErrorContains(t, err, "no description")
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t,
+		"(A) 2025-09-30 Call mum +family @phone due:2025-10-05 id:7",
+		Format(data.Task{
+			Id: 7, Title: "Call mum", Priority: "A", Project: "family", Tags: []string{"phone"},
+			DueDate:   time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC),
+			CreatedAt: localDay(2025, time.September, 30),
+		}))
+
+	assert.Equal(t,
+		"x 2025-10-02 2025-09-30 File taxes pri:B",
+		Format(data.Task{
+			Title: "File taxes", Complete: true, Priority: "B",
+			CompletedAt: localDay(2025, time.October, 2),
+			CreatedAt:   localDay(2025, time.September, 30),
+		}))
+}
+
+func TestRoundTrip(t *testing.T) {
+	tasks := []data.Task{
+		{
+			Id: 1, Title: "Call mum", Priority: "A", Project: "family", Tags: []string{"phone", "home"},
+			DueDate:   time.Date(2025, time.October, 5, 0, 0, 0, 0, time.UTC),
+			CreatedAt: localDay(2025, time.September, 30),
+		},
+		{
+			Id: 2, Title: "File taxes", Complete: true, Priority: "C",
+			CompletedAt: localDay(2025, time.October, 2),
+			CreatedAt:   localDay(2025, time.September, 1),
+		},
+		{Id: 3, Title: "Someday"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, tasks))
+	got, err := Parse(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+}
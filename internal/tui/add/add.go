@@ -5,9 +5,14 @@ import (
 
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 )
 
-func NewAdd(repository persistence.TodoRepository) *tui.Runner {
-	var model tui.Model = createModel(repository)
+func NewAdd(repository persistence.TodoRepository, t theme.Theme, keys keymap.KeyMap) *tui.Runner {
+	m := createModel(repository)
+	m.theme = t
+	m.keys = keys
+	var model tui.Model = m
 	return tui.NewRunner(context.Background(), model)
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
@@ -20,6 +22,8 @@ type model struct {
 	dueDate    string
 	err        error
 	next       tui.Command
+	theme      theme.Theme
+	keys       keymap.KeyMap
 	once       sync.Once
 }
 
@@ -35,11 +39,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	if k, ok := msg.(tea.KeyMsg); ok {
-		if k.String() == "ctrl+l" {
+		if m.keys.Matches(k, keymap.ListTasks) {
 			m.next = tui.ListTasks
 			return m, m.cleanupAndQuit()
 		}
-		c := tui.Quit(k.String(), m.Cleanup)
+		c := tui.Quit(m.keys, k, m.Cleanup)
 		if c != nil {
 			return m, c
 		}
@@ -74,19 +78,12 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m *model) View() string {
 	if m.err != nil {
-		component := tui.ErrorComponent{}
+		component := tui.ErrorComponent{Theme: m.theme}
 		return component.Render(m)
 	}
 
-	return m.form.View() + lipgloss.NewStyle().
-		Foreground(lipgloss.Color("3")).
-		Padding(1).
-		Render(`
-
-Special Shortcuts:
-ctrl + l - Go to the List View
-q/ctrl + c/esc - Quit
-`)
+	return m.form.View() + "\n\n" + lipgloss.NewStyle().Padding(1).
+		Render(m.keys.ShortHelp(m.theme, 0, keymap.ListTasks, keymap.Quit))
 }
 
 func (m *model) Cleanup() {
@@ -8,6 +8,7 @@ import (
 	"github.com/ake3mio/go-todo-cli/internal/data"
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/stretchr/testify/assert"
@@ -28,11 +29,34 @@ func (t *TestTodoRepository) SaveTask(task string, dueDate time.Time) error {
 	}{task, dueDate})
 	return nil
 }
-func (t *TestTodoRepository) GetTasks() ([]data.Task, error)      { return []data.Task{}, nil }
-func (t *TestTodoRepository) UpdateTask(task data.Task) error     { return nil }
-func (t *TestTodoRepository) UpdateTasks(tasks []data.Task) error { return nil }
-func (t *TestTodoRepository) DeleteTaskById(id int) error         { return nil }
-func (t *TestTodoRepository) Close() error                        { t.Closed++; return nil }
+func (t *TestTodoRepository) CreateTask(task data.Task) (int, error) { return 0, nil }
+func (t *TestTodoRepository) GetTask(id int) (data.Task, error) {
+	return data.Task{}, persistence.ErrTaskNotFound
+}
+func (t *TestTodoRepository) GetTasks() ([]data.Task, error) { return []data.Task{}, nil }
+func (t *TestTodoRepository) FindTasks(opts persistence.ListOptions) ([]data.Task, error) {
+	return []data.Task{}, nil
+}
+func (t *TestTodoRepository) GetCompletedTasks(since time.Time) ([]data.Task, error) {
+	return []data.Task{}, nil
+}
+func (t *TestTodoRepository) GetTaskHistory(id int) ([]data.Event, error) {
+	return []data.Event{}, nil
+}
+func (t *TestTodoRepository) UpdateTask(task data.Task) error                { return nil }
+func (t *TestTodoRepository) UpdateTasks(tasks []data.Task) error            { return nil }
+func (t *TestTodoRepository) UpsertTasks(tasks []data.Task) ([]int, error)   { return nil, nil }
+func (t *TestTodoRepository) DeleteTaskById(id int) error                    { return nil }
+func (t *TestTodoRepository) DeleteTasks(ids []int, force bool) (int, error) { return len(ids), nil }
+func (t *TestTodoRepository) DataVersion() (int64, error)                    { return 0, nil }
+func (t *TestTodoRepository) LinkTasks(blocker int, blocked []int) error     { return nil }
+func (t *TestTodoRepository) UnlinkTasks(blocker int, blocked []int) error   { return nil }
+func (t *TestTodoRepository) GetDependencies() ([]data.Dependency, error) {
+	return nil, nil
+}
+func (t *TestTodoRepository) GetStatuses() ([]data.Status, error)      { return nil, nil }
+func (t *TestTodoRepository) SetStatuses(statuses []data.Status) error { return nil }
+func (t *TestTodoRepository) Close() error                             { t.Closed++; return nil }
 
 func TestModel_InitialState(t *testing.T) {
 	repo := &TestTodoRepository{}
@@ -91,7 +115,7 @@ func TestModel_Update_QuitKeys_UseQuitHelper(t *testing.T) {
 	repo := &TestTodoRepository{}
 	m := createModel(repo)
 
-	for _, k := range tui.QuitKeys {
+	for _, k := range keymap.Defaults().Binding(keymap.Quit).Keys() {
 		next, cmd := m.Update(key(k))
 		assert.NotNil(t, cmd, "quit key %q should return a command", k)
 		assert.Same(t, m, next)
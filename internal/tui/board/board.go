@@ -0,0 +1,110 @@
+package board
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Options configures the board view.
+type Options struct {
+	Theme theme.Theme
+	Keys  keymap.KeyMap
+}
+
+func NewBoard(repository persistence.TodoRepository, options Options) *tui.Runner {
+	return tui.NewRunner(context.Background(), createModel(repository, options))
+}
+
+const (
+	// defaultColumnWidth is used until the terminal size is known.
+	defaultColumnWidth = 24
+	// minColumnWidth keeps titles readable on narrow terminals with many
+	// statuses; the board is cut off on the right instead.
+	minColumnWidth = 14
+	// chromeHeight is the lines around the columns: padding, column
+	// headers, the status line and help.
+	chromeHeight = 8
+)
+
+// columns draws one column per status side by side, each listing its tasks
+// under the status name and count.
+func (m *model) columns() string {
+	width := defaultColumnWidth
+	if m.width > 0 {
+		width = max(minColumnWidth, (m.width-2)/len(m.statuses))
+	}
+	rendered := make([]string, len(m.statuses))
+	for i := range m.statuses {
+		rendered[i] = lipgloss.NewStyle().Width(width).MaxWidth(width).PaddingRight(1).Render(m.column(i))
+	}
+	board := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	if m.width > 0 {
+		board = lipgloss.NewStyle().MaxWidth(m.width - 2).Render(board)
+	}
+	return board
+}
+
+func (m *model) column(col int) string {
+	t := m.options.Theme
+	status := m.statuses[col]
+	tasks := m.byStatus[status.Name]
+
+	role := theme.Header
+	if status.Terminal {
+		role = theme.Done
+	}
+	lines := []string{t.Style(role).Render(fmt.Sprintf("%s (%d)", status.Name, len(tasks))), ""}
+	if len(tasks) == 0 {
+		lines = append(lines, t.Style(theme.Empty).Render("-"))
+	}
+
+	start, end := 0, len(tasks)
+	if visible := m.visibleRows(); visible > 0 && len(tasks) > visible {
+		if col == m.col {
+			start = max(0, m.row-visible+1)
+		}
+		end = start + visible
+	}
+	for i := start; i < end && i < len(tasks); i++ {
+		lines = append(lines, m.card(tasks[i], col == m.col && i == m.row))
+	}
+	if rest := len(tasks) - end; rest > 0 {
+		lines = append(lines, t.Style(theme.Help).Render(fmt.Sprintf("… %d more", rest)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// card is one task on the board, coloured by how soon it is due.
+func (m *model) card(task data.Task, selected bool) string {
+	t := m.options.Theme
+	line := fmt.Sprintf("%d %s", task.Id, task.Title)
+	role := theme.Later
+	switch {
+	case task.Complete:
+		role = theme.Done
+	case !task.DueDate.IsZero():
+		role = theme.UrgencyRole(data.TaskUrgency(task, m.now()))
+	}
+	style := t.Style(role)
+	if selected {
+		style = style.Reverse(true)
+	}
+	return style.Render(line)
+}
+
+// visibleRows is how many tasks fit in a column, or zero before the
+// terminal size is known.
+func (m *model) visibleRows() int {
+	if m.height == 0 {
+		return 0
+	}
+	return max(1, m.height-chromeHeight)
+}
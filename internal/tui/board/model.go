@@ -0,0 +1,263 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type model struct {
+	repository persistence.TodoRepository
+	options    Options
+	// statuses are the board's columns, in order.
+	statuses []data.Status
+	byStatus map[string][]data.Task
+	// col and row are the selected column and the selected task within it.
+	col, row int
+	width    int
+	height   int
+	status   string
+	showHelp bool
+	version  int64
+	err      error
+	next     tui.Command
+	once     sync.Once
+	// now is the clock due dates are coloured against.
+	now func() time.Time
+}
+
+// refreshInterval is how often the board checks whether another process has
+// changed the database.
+const refreshInterval = time.Second
+
+type pollMsg struct{}
+
+func poll() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return pollMsg{} })
+}
+
+func (m *model) Init() tea.Cmd {
+	return poll()
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pollMsg:
+		m.refreshIfChanged()
+		return m, poll()
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case error:
+		m.err = msg
+		return m, nil
+	}
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.showHelp {
+		m.showHelp = false
+		return m, nil
+	}
+	m.status = ""
+	keys := m.options.Keys
+	switch {
+	case keys.Matches(k, keymap.Help):
+		m.showHelp = true
+	case keys.Matches(k, keymap.Left):
+		m.selectColumn(m.col - 1)
+	case keys.Matches(k, keymap.Right):
+		m.selectColumn(m.col + 1)
+	case keys.Matches(k, keymap.Up):
+		m.row = max(0, m.row-1)
+	case keys.Matches(k, keymap.Down):
+		m.row = max(0, min(m.row+1, len(m.selectedColumn())-1))
+	case keys.Matches(k, keymap.MoveLeft):
+		m.move(-1)
+	case keys.Matches(k, keymap.MoveRight):
+		m.move(1)
+	case keys.Matches(k, keymap.ListTasks):
+		m.next = tui.ListTasks
+		return m, m.cleanupAndQuit()
+	default:
+		return m, tui.Quit(keys, k, m.Cleanup)
+	}
+	return m, nil
+}
+
+func (m *model) selectedColumn() []data.Task {
+	if m.col >= len(m.statuses) {
+		return nil
+	}
+	return m.byStatus[m.statuses[m.col].Name]
+}
+
+// selected returns the task under the cursor, if the column has any.
+func (m *model) selected() (data.Task, bool) {
+	tasks := m.selectedColumn()
+	if m.row >= len(tasks) {
+		return data.Task{}, false
+	}
+	return tasks[m.row], true
+}
+
+// selectColumn moves the cursor to col, keeping its row where the column is
+// long enough.
+func (m *model) selectColumn(col int) {
+	m.col = max(0, min(col, len(m.statuses)-1))
+	m.row = max(0, min(m.row, len(m.selectedColumn())-1))
+}
+
+// move puts the selected task in the status by columns to the left or right
+// and keeps the cursor on it.
+func (m *model) move(by int) {
+	task, ok := m.selected()
+	to := m.col + by
+	if !ok || to < 0 || to >= len(m.statuses) {
+		return
+	}
+	task.Status = m.statuses[to].Name
+	err := m.repository.UpdateTask(task)
+	var c *persistence.ConflictError
+	var b *persistence.BlockedError
+	switch {
+	case errors.As(err, &c):
+		m.status = fmt.Sprintf("Task %d was changed elsewhere, so it was not moved. Check it and try again.", c.Id)
+	case errors.As(err, &b):
+		m.status = tui.BlockedStatus(b)
+		return
+	case err != nil:
+		m.err = err
+		return
+	}
+	if err := m.reload(); err != nil {
+		m.err = err
+		return
+	}
+	if c == nil {
+		m.follow(task.Id)
+	}
+}
+
+// follow puts the cursor on the task with id, wherever it now is.
+func (m *model) follow(id int) {
+	for col, status := range m.statuses {
+		for row, task := range m.byStatus[status.Name] {
+			if task.Id == id {
+				m.col, m.row = col, row
+				return
+			}
+		}
+	}
+}
+
+// reload rereads the statuses and tasks, keeping the cursor on the task it
+// was on.
+func (m *model) reload() error {
+	current, hadTask := m.selected()
+	statuses, err := m.repository.GetStatuses()
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		return errors.New("no statuses are defined")
+	}
+	tasks, err := m.repository.FindTasks(persistence.ListOptions{Sort: data.SortByDue})
+	if err != nil {
+		return err
+	}
+	m.statuses = statuses
+	m.byStatus = map[string][]data.Task{}
+	for _, task := range tasks {
+		m.byStatus[task.Status] = append(m.byStatus[task.Status], task)
+	}
+	m.selectColumn(m.col)
+	if hadTask {
+		m.follow(current.Id)
+	}
+	return nil
+}
+
+// refreshIfChanged reloads the board when the database has changed since it
+// was last read.
+func (m *model) refreshIfChanged() {
+	version, err := m.repository.DataVersion()
+	if err != nil {
+		m.err = err
+		return
+	}
+	if version == m.version {
+		return
+	}
+	m.version = version
+	if err := m.reload(); err != nil {
+		m.err = err
+	}
+}
+
+func (m *model) View() string {
+	t := m.options.Theme
+	if m.err != nil {
+		component := tui.ErrorComponent{Theme: t}
+		return component.Render(m)
+	}
+	keys := m.options.Keys
+	pad := lipgloss.NewStyle().Padding(1)
+	if m.showHelp {
+		return pad.Render(t.Style(theme.Header).Render("Keys") + "\n\n" +
+			keys.FullHelp(t, keymap.BoardView) + "\n\n" +
+			t.Style(theme.Help).Render("Press any key to close."))
+	}
+	help := keys.ShortHelp(t, m.width, keymap.Left, keymap.Right, keymap.MoveLeft, keymap.MoveRight, keymap.ListTasks, keymap.Help, keymap.Quit)
+	return pad.Render(m.columns()+m.statusLine()) + "\n" + lipgloss.NewStyle().Padding(0, 1).Render(help) + "\n"
+}
+
+// statusLine reports the outcome of the last move.
+func (m *model) statusLine() string {
+	if m.status == "" {
+		return ""
+	}
+	return "\n\n" + m.options.Theme.Style(theme.Empty).Render(m.status)
+}
+
+func (m *model) Cleanup() {
+	m.once.Do(func() {
+		if err := m.repository.Close(); err != nil {
+			m.err = err
+		}
+	})
+}
+
+func (m *model) cleanupAndQuit() tea.Cmd {
+	m.Cleanup()
+	return tea.Quit
+}
+
+func (m *model) Err() error { return m.err }
+
+func (m *model) Next() tui.Command { return m.next }
+
+func createModel(repository persistence.TodoRepository, options Options) *model {
+	m := &model{
+		repository: repository,
+		options:    options,
+		next:       tui.NoneTask,
+		now:        time.Now,
+	}
+	m.err = m.reload()
+	if m.err == nil {
+		m.version, m.err = repository.DataVersion()
+	}
+	return m
+}
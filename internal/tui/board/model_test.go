@@ -0,0 +1,220 @@
+package board
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var workflow = data.Statuses{{Name: "todo"}, {Name: "in-progress"}, {Name: "blocked"}, {Name: "done", Terminal: true}}
+
+// fakeRepo implements the calls the board makes; any other panics.
+type fakeRepo struct {
+	persistence.TodoRepository
+	tasks   []data.Task
+	updates []data.Task
+	version int64
+	closed  int
+	// blockers refuses to complete the tasks it lists, as open blockers do.
+	blockers map[int][]int
+}
+
+func (r *fakeRepo) GetStatuses() ([]data.Status, error) { return workflow, nil }
+
+func (r *fakeRepo) FindTasks(opts persistence.ListOptions) ([]data.Task, error) {
+	return append([]data.Task(nil), r.tasks...), nil
+}
+
+func (r *fakeRepo) UpdateTask(task data.Task) error {
+	for i := range r.tasks {
+		if r.tasks[i].Id != task.Id {
+			continue
+		}
+		if task.Version != r.tasks[i].Version {
+			return &persistence.ConflictError{Id: task.Id, Current: r.tasks[i]}
+		}
+		task, _ = workflow.Reconcile(r.tasks[i], task)
+		if task.Complete && len(r.blockers[task.Id]) > 0 {
+			return &persistence.BlockedError{Id: task.Id, Blockers: r.blockers[task.Id]}
+		}
+		task.Version++
+		r.tasks[i] = task
+	}
+	r.updates = append(r.updates, task)
+	r.version++
+	return nil
+}
+
+func (r *fakeRepo) DataVersion() (int64, error) { return r.version, nil }
+func (r *fakeRepo) Close() error                { r.closed++; return nil }
+
+func newModel(t *testing.T, tasks ...data.Task) (*model, *fakeRepo) {
+	t.Helper()
+	repo := &fakeRepo{tasks: tasks}
+	m := createModel(repo, Options{})
+	require.NoError(t, m.err)
+	m.now = func() time.Time { return time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC) }
+	return m, repo
+}
+
+func sendKey(m *model, key string) tea.Cmd {
+	var msg tea.KeyMsg
+	switch key {
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "shift+left":
+		msg = tea.KeyMsg{Type: tea.KeyShiftLeft}
+	case "shift+right":
+		msg = tea.KeyMsg{Type: tea.KeyShiftRight}
+	case "ctrl+l":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlL}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	_, cmd := m.Update(msg)
+	return cmd
+}
+
+func sampleTasks() []data.Task {
+	return []data.Task{
+		{Id: 1, Title: "Write spec", Status: "todo", Version: 1},
+		{Id: 2, Title: "Fix login", Status: "todo", Version: 1},
+		{Id: 3, Title: "Review PR", Status: "in-progress", Version: 1},
+		{Id: 4, Title: "Ship it", Status: "done", Complete: true, Version: 1},
+	}
+}
+
+func TestModel_Columns(t *testing.T) {
+	m, _ := newModel(t, sampleTasks()...)
+
+	out := m.View()
+	for _, header := range []string{"todo (2)", "in-progress (1)", "blocked (0)", "done (1)"} {
+		assert.Contains(t, out, header)
+	}
+	assert.Contains(t, out, "1 Write spec")
+	assert.Contains(t, out, "4 Ship it")
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m, _ := newModel(t, sampleTasks()...)
+
+	sendKey(m, "down")
+	assert.Equal(t, 1, m.row)
+	sendKey(m, "down")
+	assert.Equal(t, 1, m.row, "the cursor stops at the end of the column")
+
+	sendKey(m, "l")
+	assert.Equal(t, 1, m.col)
+	assert.Equal(t, 0, m.row, "the row is clamped to the shorter column")
+	sendKey(m, "right")
+	sendKey(m, "right")
+	sendKey(m, "right")
+	assert.Equal(t, 3, m.col, "the cursor stops at the last column")
+	task, ok := m.selected()
+	assert.True(t, ok)
+	assert.Equal(t, 4, task.Id)
+
+	sendKey(m, "h")
+	_, ok = m.selected()
+	assert.False(t, ok, "blocked is empty")
+}
+
+func TestModel_MoveBetweenColumns(t *testing.T) {
+	m, repo := newModel(t, sampleTasks()...)
+
+	sendKey(m, "down")
+	sendKey(m, "L")
+	require.Len(t, repo.updates, 1)
+	assert.Equal(t, "in-progress", repo.updates[0].Status)
+	assert.Equal(t, 1, m.col, "the cursor follows the task")
+	task, _ := m.selected()
+	assert.Equal(t, 2, task.Id)
+
+	sendKey(m, "shift+right")
+	sendKey(m, "shift+right")
+	assert.True(t, repo.tasks[1].Complete, "moving to a terminal status completes the task")
+	assert.Contains(t, m.View(), "done (2)")
+
+	sendKey(m, "shift+right")
+	assert.Len(t, repo.updates, 3, "there is no column past the last")
+
+	sendKey(m, "H")
+	assert.False(t, repo.tasks[1].Complete)
+	assert.Equal(t, "blocked", repo.tasks[1].Status)
+}
+
+func TestModel_MoveConflict(t *testing.T) {
+	m, repo := newModel(t, sampleTasks()...)
+	repo.tasks[0].Title = "Write the spec"
+	repo.tasks[0].Version = 2
+
+	sendKey(m, "L")
+	assert.Empty(t, repo.updates)
+	assert.Contains(t, m.View(), "Task 1 was changed elsewhere")
+	assert.Contains(t, m.View(), "1 Write the spec", "the board shows their version")
+}
+
+func TestModel_BlockedTasksStayOpen(t *testing.T) {
+	m, repo := newModel(t, sampleTasks()...)
+	repo.blockers = map[int][]int{3: {1, 2}}
+
+	sendKey(m, "right")
+	sendKey(m, "L")
+	sendKey(m, "L")
+	assert.Equal(t, "blocked", repo.tasks[2].Status)
+	assert.False(t, repo.tasks[2].Complete)
+	assert.Equal(t, 2, m.col, "the cursor stays with the task")
+	assert.Contains(t, m.View(), "Task 3 is blocked by tasks 1, 2. Complete them first.")
+}
+
+func TestModel_RefreshesOnExternalChange(t *testing.T) {
+	m, repo := newModel(t, sampleTasks()...)
+	repo.tasks = append(repo.tasks, data.Task{Id: 5, Title: "Blocked on vendor", Status: "blocked"})
+	repo.version++
+
+	m.Update(pollMsg{})
+	assert.Contains(t, m.View(), "blocked (1)")
+}
+
+func TestModel_ScrollsLongColumns(t *testing.T) {
+	var tasks []data.Task
+	for i := 1; i <= 20; i++ {
+		tasks = append(tasks, data.Task{Id: i, Title: "Task", Status: "todo"})
+	}
+	m, _ := newModel(t, tasks...)
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 13})
+
+	assert.Contains(t, m.View(), "… 15 more")
+	for range 10 {
+		sendKey(m, "down")
+	}
+	out := m.View()
+	assert.Contains(t, out, "11 Task", "the selected task stays in view")
+	assert.NotContains(t, out, "\n 1 Task")
+}
+
+func TestModel_Keys(t *testing.T) {
+	m, repo := newModel(t)
+
+	sendKey(m, "?")
+	assert.Contains(t, m.View(), "move to previous status")
+	sendKey(m, "x")
+	assert.NotContains(t, m.View(), "Press any key to close.")
+
+	cmd := sendKey(m, "ctrl+l")
+	assert.NotNil(t, cmd)
+	assert.Equal(t, tui.ListTasks, m.Next())
+	assert.Equal(t, 1, repo.closed)
+}
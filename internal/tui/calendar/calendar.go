@@ -0,0 +1,113 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+)
+
+// Options configures the calendar view.
+type Options struct {
+	Theme theme.Theme
+	Keys  keymap.KeyMap
+}
+
+func NewCalendar(repository persistence.TodoRepository, options Options) *tui.Runner {
+	return tui.NewRunner(context.Background(), createModel(repository, options))
+}
+
+// busyDay is how many open tasks make a day stand out on the grid.
+const busyDay = 5
+
+// cellWidth is the width of one day on the grid: the day of the month and
+// its count of open tasks.
+const cellWidth = 6
+
+// month draws the grid of the month cursor falls in, one row per week
+// starting on Monday. Each day shows how many open tasks are due on it;
+// days with open tasks in the past, or with busyDay or more, stand out.
+func (m *model) month() string {
+	t := m.options.Theme
+	first := time.Date(m.cursor.Year(), m.cursor.Month(), 1, 0, 0, 0, 0, time.UTC)
+	today := m.today()
+
+	var b strings.Builder
+	title := first.Format("January 2006")
+	b.WriteString(t.Style(theme.Header).Render(fmt.Sprintf("%*s", (7*cellWidth+len(title))/2, title)) + "\n\n")
+	for _, name := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		b.WriteString(t.Style(theme.Help).Render(fmt.Sprintf("%-*s", cellWidth, name)))
+	}
+	b.WriteString("\n")
+
+	// Monday is weekday 1; Sunday, weekday 0, ends the week.
+	blank := (int(first.Weekday()) + 6) % 7
+	b.WriteString(strings.Repeat(" ", blank*cellWidth))
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		b.WriteString(m.cell(day, today))
+		if day.Weekday() == time.Sunday {
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (m *model) cell(day time.Time, today time.Time) string {
+	t := m.options.Theme
+	open := m.openOn(day)
+	count := ""
+	if open > 99 {
+		count = "++"
+	} else if open > 0 {
+		count = fmt.Sprint(open)
+	}
+
+	number := fmt.Sprintf("%2d", day.Day())
+	switch {
+	case day.Equal(m.cursor):
+		number = t.Style(theme.Header).Reverse(true).Render(number)
+	case day.Equal(today):
+		number = t.Style(theme.Today).Underline(true).Render(number)
+	}
+	role := theme.Later
+	if open > 0 && day.Before(today) || open >= busyDay {
+		role = theme.Overdue
+	}
+	return number + " " + t.Style(role).Render(fmt.Sprintf("%-2s", count)) + " "
+}
+
+// summary describes the day under the cursor below the grid.
+func (m *model) summary() string {
+	tasks := m.byDay[m.cursor]
+	open := m.openOn(m.cursor)
+	line := fmt.Sprintf("%s: %d open, %d done", m.cursor.Format("Monday 2 January 2006"), open, len(tasks)-open)
+	return m.options.Theme.Style(theme.Help).Render(line)
+}
+
+func (m *model) openOn(day time.Time) int {
+	n := 0
+	for _, task := range m.byDay[day] {
+		if !task.Complete {
+			n++
+		}
+	}
+	return n
+}
+
+// move shifts the cursor by days.
+func (m *model) move(days int) {
+	m.cursor = m.cursor.AddDate(0, 0, days)
+}
+
+// moveMonth shifts the cursor by months, keeping the day of the month where
+// the new month has it and otherwise stopping at its last day.
+func (m *model) moveMonth(months int) {
+	first := time.Date(m.cursor.Year(), m.cursor.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	m.cursor = first.AddDate(0, 0, min(m.cursor.Day(), last)-1)
+}
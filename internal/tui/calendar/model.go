@@ -0,0 +1,345 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type model struct {
+	repository persistence.TodoRepository
+	options    Options
+	// byDay holds the tasks due on each day, keyed by the UTC midnight due
+	// dates are stored as.
+	byDay map[time.Time][]data.Task
+	// cursor is the day selected on the grid, as a UTC midnight.
+	cursor time.Time
+	// day lists the cursor's tasks while the day is open.
+	day      *tui.TaskList
+	edit     *edit
+	status   string
+	showHelp bool
+	version  int64
+	err      error
+	next     tui.Command
+	once     sync.Once
+	// now is the clock today is taken from.
+	now func() time.Time
+}
+
+// edit is a task field being changed from the day list.
+type edit struct {
+	task  data.Task
+	field keymap.Action
+	input textinput.Model
+}
+
+// refreshInterval is how often the calendar checks whether another process
+// has changed the database.
+const refreshInterval = time.Second
+
+type pollMsg struct{}
+
+func poll() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return pollMsg{} })
+}
+
+func (m *model) Init() tea.Cmd {
+	return poll()
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(pollMsg); ok {
+		if m.edit == nil {
+			m.refreshIfChanged()
+		}
+		return m, poll()
+	}
+	if err, ok := msg.(error); ok {
+		m.err = err
+		return m, nil
+	}
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.showHelp {
+		m.showHelp = false
+		return m, nil
+	}
+	if m.edit != nil {
+		m.updateEdit(k)
+		return m, nil
+	}
+	m.status = ""
+	keys := m.options.Keys
+	if keys.Matches(k, keymap.Help) {
+		m.showHelp = true
+		return m, nil
+	}
+	if m.day != nil {
+		return m, m.updateDay(k)
+	}
+
+	switch {
+	case keys.Matches(k, keymap.Left):
+		m.move(-1)
+	case keys.Matches(k, keymap.Right):
+		m.move(1)
+	case keys.Matches(k, keymap.Up):
+		m.move(-7)
+	case keys.Matches(k, keymap.Down):
+		m.move(7)
+	case keys.Matches(k, keymap.PrevMonth):
+		m.moveMonth(-1)
+	case keys.Matches(k, keymap.NextMonth):
+		m.moveMonth(1)
+	case keys.Matches(k, keymap.OpenDay):
+		m.openDay()
+	case keys.Matches(k, keymap.ListTasks):
+		m.next = tui.ListTasks
+		return m, m.cleanupAndQuit()
+	default:
+		return m, tui.Quit(keys, k, m.Cleanup)
+	}
+	return m, nil
+}
+
+// updateDay handles a key while a day is open. The quit keys close the day
+// rather than the calendar.
+func (m *model) updateDay(k tea.KeyMsg) tea.Cmd {
+	if m.options.Keys.Matches(k, keymap.Quit) {
+		m.day = nil
+		return nil
+	}
+	return m.day.Update(k)
+}
+
+// openDay lists the tasks due on the cursor's day.
+func (m *model) openDay() {
+	m.day = tui.NewTaskList(m.options.Theme, m.options.Keys)
+	m.day.Label = func(task data.Task) string { return task.Title }
+	m.day.Actions = []tui.TaskAction{
+		{Action: keymap.Toggle, Do: m.toggle},
+		{Action: keymap.Edit, Do: func(task *data.Task) error { m.startEdit(*task, keymap.Edit); return nil }},
+		{Action: keymap.Reschedule, Do: func(task *data.Task) error { m.startEdit(*task, keymap.Reschedule); return nil }},
+	}
+	if err := m.day.Reset(m.dayLoader()); err != nil {
+		m.err = err
+	}
+}
+
+func (m *model) dayLoader() tui.PageLoader[tui.TaskRow] {
+	return func() ([]tui.TaskRow, bool, error) {
+		tasks := m.byDay[m.cursor]
+		rows := make([]tui.TaskRow, len(tasks))
+		for i := range tasks {
+			rows[i] = tui.TaskRow{Task: &tasks[i]}
+		}
+		return rows, false, nil
+	}
+}
+
+// toggle flips task between complete and open and saves it.
+func (m *model) toggle(task *data.Task) error {
+	updated := *task
+	updated.Complete = !updated.Complete
+	return m.save(updated)
+}
+
+func (m *model) startEdit(task data.Task, field keymap.Action) {
+	e := &edit{task: task, field: field, input: textinput.New()}
+	if field == keymap.Reschedule {
+		e.input.Placeholder = time.DateOnly
+		e.input.SetValue(task.DueDate.Format(time.DateOnly))
+	} else {
+		e.input.SetValue(task.Title)
+	}
+	e.input.Focus()
+	m.edit = e
+}
+
+// updateEdit handles a key while a field is being edited: esc cancels and
+// enter saves.
+func (m *model) updateEdit(k tea.KeyMsg) {
+	e := m.edit
+	switch k.Type {
+	case tea.KeyEsc:
+		m.edit = nil
+		return
+	case tea.KeyEnter:
+		value := strings.TrimSpace(e.input.Value())
+		task := e.task
+		if e.field == keymap.Reschedule {
+			due, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				m.status = fmt.Sprintf("%q is not a date; use YYYY-MM-DD.", value)
+				return
+			}
+			task.DueDate = due
+		} else {
+			if value == "" {
+				m.status = "The title cannot be empty."
+				return
+			}
+			task.Title = value
+		}
+		m.edit = nil
+		if err := m.save(task); err != nil {
+			m.err = err
+		}
+		return
+	}
+	m.status = ""
+	e.input, _ = e.input.Update(k)
+}
+
+// save stores task and reloads the calendar. When another process changed
+// the task first nothing is stored and the calendar shows their version.
+func (m *model) save(task data.Task) error {
+	err := m.repository.UpdateTask(task)
+	var c *persistence.ConflictError
+	if errors.As(err, &c) {
+		m.status = fmt.Sprintf("Task %d was changed elsewhere, so it was not saved. Check it and try again.", c.Id)
+		return m.reload()
+	}
+	var b *persistence.BlockedError
+	if errors.As(err, &b) {
+		m.status = tui.BlockedStatus(b)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+// reload rereads every dated task, keeping an open day's cursor on the task
+// it was on.
+func (m *model) reload() error {
+	tasks, err := m.repository.FindTasks(persistence.ListOptions{Sort: data.SortByDue})
+	if err != nil {
+		return err
+	}
+	m.byDay = map[time.Time][]data.Task{}
+	for _, task := range tasks {
+		if task.DueDate.IsZero() {
+			continue
+		}
+		day := data.CalendarDay(task.DueDate, time.UTC)
+		m.byDay[day] = append(m.byDay[day], task)
+	}
+	if m.day != nil {
+		return m.day.Reload(m.dayLoader())
+	}
+	return nil
+}
+
+// refreshIfChanged reloads the calendar when the database has changed since
+// it was last read.
+func (m *model) refreshIfChanged() {
+	version, err := m.repository.DataVersion()
+	if err != nil {
+		m.err = err
+		return
+	}
+	if version == m.version {
+		return
+	}
+	m.version = version
+	if err := m.reload(); err != nil {
+		m.err = err
+	}
+}
+
+// today is the local date as a UTC midnight, to compare with due dates.
+func (m *model) today() time.Time {
+	return data.CalendarDay(m.now(), time.UTC)
+}
+
+func (m *model) View() string {
+	t := m.options.Theme
+	if m.err != nil {
+		component := tui.ErrorComponent{Theme: t}
+		return component.Render(m)
+	}
+	keys := m.options.Keys
+	pad := lipgloss.NewStyle().Padding(1)
+	if m.showHelp {
+		return pad.Render(t.Style(theme.Header).Render("Keys") + "\n\n" +
+			keys.FullHelp(t, keymap.CalendarView) + "\n\n" +
+			t.Style(theme.Help).Render("Press any key to close."))
+	}
+	if m.edit != nil {
+		title := fmt.Sprintf("Rename task %d", m.edit.task.Id)
+		if m.edit.field == keymap.Reschedule {
+			title = fmt.Sprintf("Move task %d to", m.edit.task.Id)
+		}
+		return pad.Render(t.Style(theme.Header).Render(title) + "\n\n" + m.edit.input.View() + "\n\n" +
+			t.Style(theme.Help).Render("enter - Save\nesc - Cancel") + m.statusLine())
+	}
+	if m.day != nil {
+		body := t.Style(theme.Header).Render(m.cursor.Format("Monday 2 January 2006")) + "\n\n"
+		if len(m.byDay[m.cursor]) == 0 {
+			body += t.Style(theme.Empty).Render("Nothing is due on this day.")
+		} else {
+			body += m.day.View()
+		}
+		help := keys.ShortHelp(t, 0, keymap.Toggle, keymap.Edit, keymap.Reschedule, keymap.Help, keymap.Quit)
+		return pad.Render(body+m.statusLine()) + "\n" + lipgloss.NewStyle().Padding(0, 1).Render(help) + "\n"
+	}
+	help := keys.ShortHelp(t, 0, keymap.OpenDay, keymap.PrevMonth, keymap.NextMonth, keymap.ListTasks, keymap.Help, keymap.Quit)
+	return pad.Render(m.month()+"\n\n"+m.summary()+m.statusLine()) + "\n" +
+		lipgloss.NewStyle().Padding(0, 1).Render(help) + "\n"
+}
+
+// statusLine reports the outcome of the last change.
+func (m *model) statusLine() string {
+	if m.status == "" {
+		return ""
+	}
+	return "\n\n" + m.options.Theme.Style(theme.Empty).Render(m.status)
+}
+
+func (m *model) Cleanup() {
+	m.once.Do(func() {
+		if err := m.repository.Close(); err != nil {
+			m.err = err
+		}
+	})
+}
+
+func (m *model) cleanupAndQuit() tea.Cmd {
+	m.Cleanup()
+	return tea.Quit
+}
+
+func (m *model) Err() error { return m.err }
+
+func (m *model) Next() tui.Command { return m.next }
+
+func createModel(repository persistence.TodoRepository, options Options) *model {
+	m := &model{
+		repository: repository,
+		options:    options,
+		next:       tui.NoneTask,
+		now:        time.Now,
+	}
+	m.cursor = m.today()
+	m.err = m.reload()
+	if m.err == nil {
+		m.version, m.err = repository.DataVersion()
+	}
+	return m
+}
@@ -0,0 +1,214 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepo implements the calls the calendar makes; any other panics.
+type fakeRepo struct {
+	persistence.TodoRepository
+	tasks   []data.Task
+	updates []data.Task
+	version int64
+	closed  int
+}
+
+func (r *fakeRepo) FindTasks(opts persistence.ListOptions) ([]data.Task, error) {
+	return append([]data.Task(nil), r.tasks...), nil
+}
+
+func (r *fakeRepo) UpdateTask(task data.Task) error {
+	for i := range r.tasks {
+		if r.tasks[i].Id != task.Id {
+			continue
+		}
+		if task.Version != r.tasks[i].Version {
+			return &persistence.ConflictError{Id: task.Id, Current: r.tasks[i]}
+		}
+		task.Version++
+		r.tasks[i] = task
+	}
+	r.updates = append(r.updates, task)
+	r.version++
+	return nil
+}
+
+func (r *fakeRepo) DataVersion() (int64, error) { return r.version, nil }
+func (r *fakeRepo) Close() error                { r.closed++; return nil }
+
+func day(d int) time.Time { return time.Date(2025, time.October, d, 0, 0, 0, 0, time.UTC) }
+
+func newModel(t *testing.T, tasks ...data.Task) (*model, *fakeRepo) {
+	t.Helper()
+	repo := &fakeRepo{tasks: tasks}
+	m := createModel(repo, Options{})
+	require.NoError(t, m.err)
+	// Wednesday 1 October 2025.
+	m.now = func() time.Time { return time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC) }
+	m.cursor = m.today()
+	return m, repo
+}
+
+func sendKey(m *model, key string) tea.Cmd {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "left":
+		msg = tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		msg = tea.KeyMsg{Type: tea.KeyRight}
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "pgup":
+		msg = tea.KeyMsg{Type: tea.KeyPgUp}
+	case "pgdown":
+		msg = tea.KeyMsg{Type: tea.KeyPgDown}
+	case "ctrl+l":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlL}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	_, cmd := m.Update(msg)
+	return cmd
+}
+
+func typeText(m *model, s string) {
+	for _, r := range s {
+		sendKey(m, string(r))
+	}
+}
+
+func TestModel_MonthGrid(t *testing.T) {
+	m, _ := newModel(t,
+		data.Task{Id: 1, Title: "Pay rent", DueDate: day(1)},
+		data.Task{Id: 2, Title: "Report", DueDate: day(1)},
+		data.Task{Id: 3, Title: "Filed", DueDate: day(1), Complete: true},
+		data.Task{Id: 4, Title: "Dentist", DueDate: day(9)},
+		data.Task{Id: 5, Title: "Someday"},
+	)
+
+	out := m.View()
+	assert.Contains(t, out, "October 2025")
+	assert.Contains(t, out, "Mo    Tu    We    Th    Fr    Sa    Su")
+	// October 2025 starts on a Wednesday.
+	assert.Contains(t, out, "             1 2   ")
+	assert.Contains(t, out, " 9 1 ")
+	assert.Contains(t, out, "Wednesday 1 October 2025: 2 open, 1 done")
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m, _ := newModel(t)
+
+	sendKey(m, "right")
+	assert.Equal(t, day(2), m.cursor)
+	sendKey(m, "down")
+	assert.Equal(t, day(9), m.cursor)
+	sendKey(m, "h")
+	assert.Equal(t, day(8), m.cursor)
+	sendKey(m, "k")
+	assert.Equal(t, day(1), m.cursor)
+	sendKey(m, "left")
+	assert.Equal(t, time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC), m.cursor, "days cross into the previous month")
+	assert.Contains(t, m.View(), "September 2025")
+
+	m.cursor = time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)
+	sendKey(m, "pgdown")
+	assert.Equal(t, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC), m.cursor, "the day is clamped to the month's end")
+	sendKey(m, "]")
+	assert.Equal(t, time.Date(2026, time.March, 28, 0, 0, 0, 0, time.UTC), m.cursor)
+	sendKey(m, "pgup")
+	sendKey(m, "[")
+	assert.Equal(t, time.Date(2026, time.January, 28, 0, 0, 0, 0, time.UTC), m.cursor)
+}
+
+func TestModel_DayDrillDown(t *testing.T) {
+	m, repo := newModel(t,
+		data.Task{Id: 1, Title: "Pay rent", DueDate: day(1), Version: 1},
+		data.Task{Id: 2, Title: "Report", DueDate: day(1), Version: 1},
+		data.Task{Id: 4, Title: "Dentist", DueDate: day(9), Version: 1},
+	)
+
+	sendKey(m, "enter")
+	out := m.View()
+	assert.Contains(t, out, "Wednesday 1 October 2025")
+	assert.Contains(t, out, "[ ] 1 - Pay rent")
+	assert.Contains(t, out, "[ ] 2 - Report")
+	assert.NotContains(t, out, "Dentist")
+
+	sendKey(m, "x")
+	require.Len(t, repo.updates, 1)
+	assert.True(t, repo.updates[0].Complete)
+	assert.Contains(t, m.View(), "[x] 1 - Pay rent")
+
+	sendKey(m, "j")
+	sendKey(m, "e")
+	assert.Contains(t, m.View(), "Rename task 2")
+	for range len("Report") {
+		m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	typeText(m, "Quarterly report")
+	sendKey(m, "enter")
+	assert.Equal(t, "Quarterly report", repo.tasks[1].Title)
+	assert.Contains(t, m.View(), "[ ] 2 - Quarterly report")
+
+	sendKey(m, "r")
+	assert.Contains(t, m.View(), "Move task 2 to")
+	for range len("2025-10-01") {
+		m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	typeText(m, "2025-10-09")
+	sendKey(m, "enter")
+	assert.Equal(t, day(9), repo.tasks[1].DueDate)
+	assert.NotContains(t, m.View(), "Quarterly report", "moved tasks leave the day")
+
+	sendKey(m, "q")
+	assert.Nil(t, m.day, "quit closes the day first")
+	assert.Zero(t, repo.closed)
+	assert.Contains(t, m.View(), " 9 2 ")
+}
+
+func TestModel_EditConflictSavesNothing(t *testing.T) {
+	m, repo := newModel(t, data.Task{Id: 1, Title: "Pay rent", DueDate: day(1), Version: 1})
+
+	sendKey(m, "enter")
+	sendKey(m, "e")
+	repo.tasks[0].Title = "Pay the rent"
+	repo.tasks[0].Version = 2
+	typeText(m, "!")
+	sendKey(m, "enter")
+
+	assert.Empty(t, repo.updates)
+	out := m.View()
+	assert.Contains(t, out, "Task 1 was changed elsewhere")
+	assert.Contains(t, out, "1 - Pay the rent", "their version is shown")
+}
+
+func TestModel_SwitchToList(t *testing.T) {
+	m, repo := newModel(t)
+
+	cmd := sendKey(m, "ctrl+l")
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "", string(m.Next()))
+	assert.Equal(t, 1, repo.closed)
+}
+
+func TestModel_PicksUpChangesFromElsewhere(t *testing.T) {
+	m, repo := newModel(t)
+	repo.tasks = append(repo.tasks, data.Task{Id: 7, Title: "Added", DueDate: day(3)})
+	repo.version++
+
+	m.Update(pollMsg{})
+	assert.Equal(t, 1, m.openOn(day(3)))
+}
@@ -6,4 +6,6 @@ const (
 	NoneTask  Command = "-"
 	AddTask   Command = "add"
 	ListTasks Command = ""
+	Calendar  Command = "calendar"
+	Board     Command = "board"
 )
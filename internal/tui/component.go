@@ -3,17 +3,18 @@ package tui
 import (
 	"fmt"
 
-	"github.com/charmbracelet/lipgloss"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 )
 
 type Component interface {
 	Render(model Model) string
 }
 
-type ErrorComponent struct{}
+type ErrorComponent struct {
+	Theme theme.Theme
+}
 
 func (e ErrorComponent) Render(model Model) string {
-	return lipgloss.NewStyle().
-		Foreground(lipgloss.Color("1")).
+	return e.Theme.Style(theme.Error).
 		Render(fmt.Sprintf("Error: %v\n", model.Err()))
 }
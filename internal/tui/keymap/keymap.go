@@ -0,0 +1,369 @@
+package keymap
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Action names something a key does. Actions are the keys of the
+// "bindings" config section.
+type Action string
+
+const (
+	Up            Action = "up"
+	Down          Action = "down"
+	Left          Action = "left"
+	Right         Action = "right"
+	PrevMonth     Action = "previous-month"
+	NextMonth     Action = "next-month"
+	MoveLeft      Action = "move-left"
+	MoveRight     Action = "move-right"
+	PageUp        Action = "page-up"
+	PageDown      Action = "page-down"
+	Top           Action = "top"
+	Bottom        Action = "bottom"
+	Select        Action = "select"
+	Visual        Action = "visual"
+	Toggle        Action = "toggle"
+	Delete        Action = "delete"
+	Reschedule    Action = "reschedule"
+	Tag           Action = "tag"
+	Protect       Action = "protect"
+	RollOver      Action = "roll-over"
+	Edit          Action = "edit"
+	OpenDay       Action = "open-day"
+	HideCompleted Action = "hide-completed"
+	DetailTab     Action = "detail-tab"
+	AddTask       Action = "add"
+	ListTasks     Action = "list"
+	Calendar      Action = "calendar"
+	Board         Action = "board"
+	Done          Action = "done"
+	Help          Action = "help"
+	Quit          Action = "quit"
+
+	// The list's prompts take over the keyboard while open, so their keys
+	// only have to differ from each other.
+	SnoozeToday     Action = "snooze-today"
+	SnoozeTomorrow  Action = "snooze-tomorrow"
+	SnoozeNextWeek  Action = "snooze-next-week"
+	SnoozeNextMonth Action = "snooze-next-month"
+	PickDate        Action = "pick-date"
+	Confirm         Action = "confirm"
+	Decline         Action = "decline"
+	Overwrite       Action = "overwrite"
+	KeepTheirs      Action = "keep-theirs"
+	Cancel          Action = "cancel"
+)
+
+const (
+	Default = "default"
+	Vim     = "vim"
+	Emacs   = "emacs"
+)
+
+// View is a screen whose keys must not clash with each other.
+type View string
+
+const (
+	ListView     View = "list"
+	AddView      View = "add"
+	CalendarView View = "calendar"
+	BoardView    View = "board"
+	// RescheduleView, ConfirmView and ConflictView are the list's
+	// reschedule menu, delete confirmation and conflict prompts.
+	RescheduleView View = "reschedule"
+	ConfirmView    View = "confirm"
+	ConflictView   View = "conflict"
+)
+
+// views lists every view, in the order their keys are checked.
+var views = []View{ListView, AddView, CalendarView, BoardView, RescheduleView, ConfirmView, ConflictView}
+
+type action struct {
+	name  Action
+	desc  string
+	views []View
+}
+
+// actions lists every action in the order help shows them.
+var actions = []action{
+	{Up, "up", []View{ListView, CalendarView, BoardView}},
+	{Down, "down", []View{ListView, CalendarView, BoardView}},
+	{Left, "left", []View{CalendarView, BoardView}},
+	{Right, "right", []View{CalendarView, BoardView}},
+	{MoveLeft, "move to previous status", []View{BoardView}},
+	{MoveRight, "move to next status", []View{BoardView}},
+	{PrevMonth, "previous month", []View{CalendarView}},
+	{NextMonth, "next month", []View{CalendarView}},
+	{PageUp, "page up", []View{ListView}},
+	{PageDown, "page down", []View{ListView}},
+	{Top, "top", []View{ListView}},
+	{Bottom, "bottom", []View{ListView}},
+	{Select, "select", []View{ListView}},
+	{Visual, "select range", []View{ListView}},
+	{Toggle, "toggle complete", []View{ListView, CalendarView}},
+	{Delete, "delete", []View{ListView}},
+	{Reschedule, "reschedule", []View{ListView, CalendarView}},
+	{Tag, "tag", []View{ListView}},
+	{Protect, "protect", []View{ListView}},
+	{RollOver, "overdue to today", []View{ListView}},
+	{Edit, "edit title", []View{CalendarView}},
+	{OpenDay, "open day", []View{CalendarView}},
+	{HideCompleted, "hide completed", []View{ListView}},
+	{DetailTab, "details/history", []View{ListView}},
+	{AddTask, "add task", []View{ListView}},
+	{ListTasks, "task list", []View{AddView, CalendarView, BoardView}},
+	{Calendar, "calendar", []View{ListView}},
+	{Board, "board", []View{ListView}},
+	{Done, "save and quit", []View{ListView}},
+	{Help, "help", []View{ListView, CalendarView, BoardView}},
+	{Quit, "quit", []View{ListView, AddView, CalendarView, BoardView}},
+	{SnoozeToday, "today", []View{RescheduleView}},
+	{SnoozeTomorrow, "tomorrow", []View{RescheduleView}},
+	{SnoozeNextWeek, "next week (Monday)", []View{RescheduleView}},
+	{SnoozeNextMonth, "next month (the 1st)", []View{RescheduleView}},
+	{PickDate, "pick a date", []View{RescheduleView}},
+	{Confirm, "delete", []View{ConfirmView}},
+	{Decline, "keep", []View{ConfirmView}},
+	{Overwrite, "overwrite with yours", []View{ConflictView}},
+	{KeepTheirs, "keep theirs", []View{ConflictView}},
+	{Cancel, "cancel", []View{RescheduleView, ConfirmView}},
+}
+
+var defaults = map[Action][]string{
+	Up:            {"up", "k"},
+	Down:          {"down", "j"},
+	Left:          {"left", "h"},
+	Right:         {"right", "l"},
+	PrevMonth:     {"pgup", "["},
+	NextMonth:     {"pgdown", "]"},
+	MoveLeft:      {"shift+left", "H"},
+	MoveRight:     {"shift+right", "L"},
+	PageUp:        {"pgup"},
+	PageDown:      {"pgdown"},
+	Top:           {"home", "g"},
+	Bottom:        {"end", "G"},
+	Select:        {" "},
+	Visual:        {"V"},
+	Toggle:        {"x"},
+	Delete:        {"delete", "backspace"},
+	Reschedule:    {"r"},
+	Tag:           {"t"},
+	Protect:       {"p"},
+	RollOver:      {"R"},
+	Edit:          {"e"},
+	OpenDay:       {"enter"},
+	HideCompleted: {"ctrl+h"},
+	DetailTab:     {"ctrl+t"},
+	AddTask:       {"ctrl+a"},
+	ListTasks:     {"ctrl+l"},
+	Calendar:      {"C"},
+	Board:         {"B"},
+	Done:          {"enter"},
+	Help:          {"?"},
+	Quit:          {"q", "esc", "ctrl+c"},
+
+	SnoozeToday:     {"t"},
+	SnoozeTomorrow:  {"d"},
+	SnoozeNextWeek:  {"w"},
+	SnoozeNextMonth: {"m"},
+	PickDate:        {"p"},
+	Confirm:         {"y"},
+	Decline:         {"n"},
+	Overwrite:       {"o"},
+	KeepTheirs:      {"t", "esc"},
+	Cancel:          {"esc"},
+}
+
+// presets override the defaults.
+var presets = map[string]map[Action][]string{
+	Default: {},
+	Vim: {
+		PageUp:   {"ctrl+u", "pgup"},
+		PageDown: {"ctrl+d", "pgdown"},
+		Delete:   {"d", "delete"},
+		AddTask:  {"a", "ctrl+a"},
+	},
+	Emacs: {
+		Up:       {"ctrl+p", "up"},
+		Down:     {"ctrl+n", "down"},
+		Left:     {"ctrl+b", "left"},
+		Right:    {"ctrl+f", "right"},
+		PageUp:   {"alt+v", "pgup"},
+		PageDown: {"ctrl+v", "pgdown"},
+		Top:      {"alt+<", "home"},
+		Bottom:   {"alt+>", "end"},
+		Select:   {"ctrl+@", " "},
+		Delete:   {"ctrl+d", "delete"},
+		Quit:     {"ctrl+g", "ctrl+c", "esc"},
+	},
+}
+
+// KeyMap binds each Action to its keys.
+type KeyMap struct {
+	Name     string
+	bindings map[Action]key.Binding
+}
+
+// Presets lists the names of the built-in key maps.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve builds the preset named preset (default when empty) and rebinds
+// the actions in overrides, each to the keys listed; an empty list unbinds
+// it. A key bound to two actions of the same view is an error.
+func Resolve(preset string, overrides map[string][]string) (KeyMap, error) {
+	if preset == "" {
+		preset = Default
+	}
+	keys, ok := presets[preset]
+	if !ok {
+		return KeyMap{}, fmt.Errorf("unknown key preset %q", preset)
+	}
+	k := KeyMap{Name: preset, bindings: make(map[Action]key.Binding, len(actions))}
+	for _, a := range actions {
+		bound, ok := keys[a.name]
+		if !ok {
+			bound = defaults[a.name]
+		}
+		k.bindings[a.name] = binding(a, bound)
+	}
+	for name, bound := range overrides {
+		a, ok := find(Action(name))
+		if !ok {
+			return KeyMap{}, fmt.Errorf("keys: unknown action %q", name)
+		}
+		k.bindings[a.name] = binding(a, bound)
+	}
+	return k, k.conflicts()
+}
+
+var defaultKeyMap, _ = Resolve(Default, nil)
+
+// Defaults is the key map used when none is configured.
+func Defaults() KeyMap {
+	return defaultKeyMap
+}
+
+func find(name Action) (action, bool) {
+	i := slices.IndexFunc(actions, func(a action) bool { return a.name == name })
+	if i < 0 {
+		return action{}, false
+	}
+	return actions[i], true
+}
+
+func binding(a action, keys []string) key.Binding {
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		shown[i] = keyName(k)
+	}
+	b := key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(shown, "/"), a.desc))
+	if len(keys) == 0 {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// keyName spells out keys that do not show up well on their own.
+func keyName(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "ctrl+@":
+		return "ctrl+space"
+	}
+	return k
+}
+
+// conflicts reports the first key bound to two actions of the same view.
+func (k KeyMap) conflicts() error {
+	for _, view := range views {
+		owner := map[string]Action{}
+		for _, a := range actions {
+			if !slices.Contains(a.views, view) {
+				continue
+			}
+			for _, bound := range k.bindings[a.name].Keys() {
+				if other, ok := owner[bound]; ok && other != a.name {
+					return fmt.Errorf("keys: %q is bound to both %s and %s", keyName(bound), other, a.name)
+				}
+				owner[bound] = a.name
+			}
+		}
+	}
+	return nil
+}
+
+// Binding returns the keys bound to a. A zero KeyMap falls back to the
+// defaults so components can hold one without initialising it.
+func (k KeyMap) Binding(a Action) key.Binding {
+	if k.bindings == nil {
+		return defaultKeyMap.bindings[a]
+	}
+	return k.bindings[a]
+}
+
+// Matches reports whether msg is one of the keys bound to a.
+func (k KeyMap) Matches(msg tea.KeyMsg, a Action) bool {
+	return key.Matches(msg, k.Binding(a))
+}
+
+// ShortHelp renders the bindings of actions on one line, cut to width.
+func (k KeyMap) ShortHelp(t theme.Theme, width int, actions ...Action) string {
+	return newHelp(t, width).ShortHelpView(k.bindingsOf(actions))
+}
+
+// FullHelp renders every action of view, a column per group.
+func (k KeyMap) FullHelp(t theme.Theme, view View) string {
+	var groups [][]key.Binding
+	for _, group := range [][]Action{
+		{Up, Down, Left, Right, PrevMonth, NextMonth, PageUp, PageDown, Top, Bottom},
+		{OpenDay, MoveLeft, MoveRight, Select, Visual, Toggle, Edit, Delete, Reschedule, RollOver, Tag, Protect, HideCompleted, DetailTab},
+		{AddTask, ListTasks, Calendar, Board, Done, Help, Quit},
+		{SnoozeToday, SnoozeTomorrow, SnoozeNextWeek, SnoozeNextMonth, PickDate, Confirm, Decline, Overwrite, KeepTheirs, Cancel},
+	} {
+		var inView []Action
+		for _, name := range group {
+			if a, _ := find(name); slices.Contains(a.views, view) {
+				inView = append(inView, name)
+			}
+		}
+		groups = append(groups, k.bindingsOf(inView))
+	}
+	return newHelp(t, 0).FullHelpView(groups)
+}
+
+func (k KeyMap) bindingsOf(actions []Action) []key.Binding {
+	bindings := make([]key.Binding, len(actions))
+	for i, a := range actions {
+		bindings[i] = k.Binding(a)
+	}
+	return bindings
+}
+
+func newHelp(t theme.Theme, width int) help.Model {
+	h := help.New()
+	h.Width = width
+	keyStyle := t.Style(theme.Header)
+	descStyle := t.Style(theme.Help)
+	h.Styles.ShortKey, h.Styles.FullKey = keyStyle, keyStyle
+	h.Styles.ShortDesc, h.Styles.FullDesc = descStyle, descStyle
+	h.Styles.ShortSeparator, h.Styles.FullSeparator = descStyle, descStyle
+	h.Styles.Ellipsis = descStyle
+	return h
+}
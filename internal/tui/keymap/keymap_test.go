@@ -0,0 +1,97 @@
+package keymap
+
+import (
+	"testing"
+
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve_Presets(t *testing.T) {
+	assert.Equal(t, []string{Default, Emacs, Vim}, Presets())
+
+	for _, name := range Presets() {
+		k, err := Resolve(name, nil)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, k.Name)
+	}
+
+	vim, _ := Resolve(Vim, nil)
+	assert.True(t, vim.Matches(tea.KeyMsg{Type: tea.KeyCtrlD}, PageDown))
+	assert.True(t, vim.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}, Delete))
+	assert.True(t, vim.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, Down), "unset actions keep their defaults")
+
+	emacs, _ := Resolve(Emacs, nil)
+	assert.True(t, emacs.Matches(tea.KeyMsg{Type: tea.KeyCtrlN}, Down))
+	assert.True(t, emacs.Matches(tea.KeyMsg{Type: tea.KeyCtrlAt}, Select))
+	assert.False(t, emacs.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}, Quit))
+
+	_, err := Resolve("nano", nil)
+	assert.EqualError(t, err, `unknown key preset "nano"`)
+}
+
+func TestResolve_Overrides(t *testing.T) {
+	k, err := Resolve("", map[string][]string{"toggle": {"c", "x"}, "help": {}})
+	require.NoError(t, err)
+	assert.Equal(t, Default, k.Name)
+	assert.True(t, k.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")}, Toggle))
+	assert.Equal(t, "c/x", k.Binding(Toggle).Help().Key)
+	assert.False(t, k.Binding(Help).Enabled(), "an empty list unbinds the action")
+
+	_, err = Resolve("", map[string][]string{"teleport": {"t"}})
+	assert.EqualError(t, err, `keys: unknown action "teleport"`)
+}
+
+func TestResolve_RejectsConflicts(t *testing.T) {
+	_, err := Resolve("", map[string][]string{"toggle": {"j"}})
+	assert.EqualError(t, err, `keys: "j" is bound to both down and toggle`)
+
+	_, err = Resolve(Vim, map[string][]string{"select": {"d"}})
+	assert.EqualError(t, err, `keys: "d" is bound to both select and delete`)
+
+	_, err = Resolve("", map[string][]string{"list": {"ctrl+a"}})
+	assert.NoError(t, err, "actions of different views may share keys")
+
+	_, err = Resolve("", map[string][]string{"snooze-today": {"d"}})
+	assert.EqualError(t, err, `keys: "d" is bound to both snooze-today and snooze-tomorrow`, "prompt keys are checked too")
+	_, err = Resolve("", map[string][]string{"overwrite": {"t"}})
+	assert.EqualError(t, err, `keys: "t" is bound to both overwrite and keep-theirs`)
+}
+
+func TestKeyMap_ZeroValueUsesDefaults(t *testing.T) {
+	var k KeyMap
+	assert.True(t, k.Matches(tea.KeyMsg{Type: tea.KeySpace}, Select))
+	assert.Equal(t, "space", k.Binding(Select).Help().Key)
+}
+
+func TestKeyMap_Help(t *testing.T) {
+	k, _ := Resolve(Vim, nil)
+
+	short := k.ShortHelp(theme.Default(), 0, Toggle, Help, Quit)
+	assert.Contains(t, short, "x toggle complete")
+	assert.Contains(t, short, "? help")
+
+	full := k.FullHelp(theme.Default(), ListView)
+	assert.Contains(t, full, "ctrl+d/pgdown")
+	assert.Contains(t, full, "d/delete")
+	assert.Contains(t, full, "save and quit")
+	assert.NotContains(t, full, "task list", "actions of other views are left out")
+
+	calendar := k.FullHelp(theme.Default(), CalendarView)
+	assert.Contains(t, calendar, "pgup/[")
+	assert.Contains(t, calendar, "open day")
+	assert.NotContains(t, calendar, "select range")
+
+	board := k.FullHelp(theme.Default(), BoardView)
+	assert.Contains(t, board, "shift+left/H")
+	assert.Contains(t, board, "move to next status")
+	assert.NotContains(t, board, "open day")
+
+	reschedule := k.FullHelp(theme.Default(), RescheduleView)
+	assert.Contains(t, reschedule, "next week (Monday)")
+	assert.Contains(t, reschedule, "pick a date")
+	assert.NotContains(t, reschedule, "toggle complete")
+	assert.NotContains(t, full, "pick a date", "prompt keys are not the list's")
+}
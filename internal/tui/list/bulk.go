@@ -0,0 +1,289 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type promptKind int
+
+const (
+	reschedulePrompt promptKind = iota
+	datePrompt
+	tagPrompt
+	deletePrompt
+)
+
+// prompt asks how to reschedule or tag the tasks an action applies to, or
+// whether to delete them.
+type prompt struct {
+	kind  promptKind
+	tasks []*data.Task
+	input textinput.Model
+}
+
+func newPrompt(kind promptKind, tasks []*data.Task) *prompt {
+	p := &prompt{kind: kind, tasks: tasks, input: textinput.New()}
+	switch kind {
+	case datePrompt:
+		p.input.Placeholder = time.DateOnly
+	case tagPrompt:
+		p.input.Placeholder = "tag, or -tag to remove it"
+	}
+	p.input.Focus()
+	return p
+}
+
+// snoozeKeys are the reschedule menu's choices, in the order it lists them.
+var snoozeKeys = []struct {
+	action keymap.Action
+	when   string
+}{
+	{keymap.SnoozeToday, data.SnoozeToday},
+	{keymap.SnoozeTomorrow, data.SnoozeTomorrow},
+	{keymap.SnoozeNextWeek, data.SnoozeNextWeek},
+	{keymap.SnoozeNextMonth, data.SnoozeNextMonth},
+}
+
+// updatePrompt handles a key pressed while the prompt is open. Cancel
+// closes it; the reschedule menu takes one of snoozeKeys or PickDate; the
+// date and tag inputs apply on enter.
+func (m *model) updatePrompt(k tea.KeyMsg) error {
+	p := m.prompt
+	keys := m.options.Keys
+	if keys.Matches(k, keymap.Cancel) {
+		m.prompt = nil
+		return nil
+	}
+	switch p.kind {
+	case deletePrompt:
+		switch {
+		case keys.Matches(k, keymap.Confirm):
+			m.prompt = nil
+			return m.deleteAll(p.tasks)
+		case keys.Matches(k, keymap.Decline):
+			m.prompt = nil
+		}
+		return nil
+	case reschedulePrompt:
+		if keys.Matches(k, keymap.PickDate) {
+			m.prompt = newPrompt(datePrompt, p.tasks)
+			return nil
+		}
+		for _, s := range snoozeKeys {
+			if keys.Matches(k, s.action) {
+				due, _ := data.ParseSnooze(s.when, m.now())
+				return m.applyPrompt(func(task *data.Task) { task.DueDate = due })
+			}
+		}
+		return nil
+	case datePrompt:
+		if k.Type != tea.KeyEnter {
+			break
+		}
+		due, err := time.Parse(time.DateOnly, strings.TrimSpace(p.input.Value()))
+		if err != nil {
+			m.status = fmt.Sprintf("%q is not a date; use YYYY-MM-DD.", p.input.Value())
+			return nil
+		}
+		return m.applyPrompt(func(task *data.Task) { task.DueDate = due })
+	case tagPrompt:
+		if k.Type != tea.KeyEnter {
+			break
+		}
+		tag, remove := strings.CutPrefix(strings.TrimSpace(p.input.Value()), "-")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			m.prompt = nil
+			return nil
+		}
+		// Tags are stored comma-separated, as the API also insists.
+		if strings.Contains(tag, ",") {
+			m.status = fmt.Sprintf("Tag %q must not contain a comma.", tag)
+			return nil
+		}
+		if remove {
+			return m.applyPrompt(func(task *data.Task) {
+				task.Tags = slices.DeleteFunc(slices.Clone(task.Tags), func(t string) bool { return t == tag })
+			})
+		}
+		return m.applyPrompt(func(task *data.Task) {
+			if !slices.Contains(task.Tags, tag) {
+				task.Tags = append(slices.Clone(task.Tags), tag)
+			}
+		})
+	}
+	m.status = ""
+	p.input, _ = p.input.Update(k)
+	return nil
+}
+
+func (m *model) applyPrompt(change func(task *data.Task)) error {
+	tasks := m.prompt.tasks
+	m.prompt = nil
+	return m.updateAll(tasks, change)
+}
+
+// rollOver moves every overdue task, loaded or not, to today.
+func (m *model) rollOver() error {
+	tasks, err := m.repository.FindTasks(persistence.ListOptions{HideCompleted: true})
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	var overdue []*data.Task
+	for i := range tasks {
+		if data.IsOverdue(tasks[i], now) {
+			overdue = append(overdue, &tasks[i])
+		}
+	}
+	if len(overdue) == 0 {
+		m.status = "Nothing is overdue."
+		return nil
+	}
+	today, _ := data.ParseSnooze(data.SnoozeToday, now)
+	return m.updateAll(overdue, func(task *data.Task) { task.DueDate = today })
+}
+
+// completeAll marks tasks complete, or open again when they all already
+// are.
+func (m *model) completeAll(tasks []*data.Task) error {
+	complete := slices.ContainsFunc(tasks, func(task *data.Task) bool { return !task.Complete })
+	return m.updateAll(tasks, func(task *data.Task) { task.Complete = complete })
+}
+
+// updateAll applies change to copies of tasks and stores them in one
+// transaction. When another process changed one of them first nothing is
+// stored: the list is reloaded, keeping the selection, so the user can look
+// again and retry.
+func (m *model) updateAll(tasks []*data.Task, change func(task *data.Task)) error {
+	updated := make([]data.Task, len(tasks))
+	for i, task := range tasks {
+		updated[i] = *task
+		change(&updated[i])
+	}
+	err := m.repository.UpdateTasks(updated)
+	var c *persistence.ConflictError
+	if errors.As(err, &c) {
+		m.status = fmt.Sprintf("Task %d was changed elsewhere, so nothing was changed. Check the list and try again.", c.Id)
+		m.refresh()
+		return nil
+	}
+	var b *persistence.BlockedError
+	if errors.As(err, &b) {
+		m.status = tui.BlockedStatus(b)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.status = fmt.Sprintf("Updated %s.", plural(len(tasks), "task"))
+	m.list.ClearSelection()
+	m.refresh()
+	return nil
+}
+
+// protectAll protects tasks from deletion, or unprotects them when they all
+// already are.
+func (m *model) protectAll(tasks []*data.Task) error {
+	protect := slices.ContainsFunc(tasks, func(task *data.Task) bool { return !task.Protected })
+	return m.updateAll(tasks, func(task *data.Task) { task.Protected = protect })
+}
+
+// requestDelete deletes tasks, first asking when the ConfirmDelete option
+// says to. Protected tasks are never deleted from the list.
+func (m *model) requestDelete(tasks []*data.Task) error {
+	if i := slices.IndexFunc(tasks, func(task *data.Task) bool { return task.Protected }); i >= 0 {
+		m.status = m.protectedStatus(tasks[i].Id)
+		return nil
+	}
+	if m.options.ConfirmDelete.needsConfirm(tasks) {
+		m.prompt = newPrompt(deletePrompt, tasks)
+		return nil
+	}
+	return m.deleteAll(tasks)
+}
+
+func (m *model) deleteOne(task *data.Task) error {
+	return m.requestDelete([]*data.Task{task})
+}
+
+// protectedStatus names the key that unprotects, as it is bound.
+func (m *model) protectedStatus(id int) string {
+	return fmt.Sprintf("Task %d is protected. Press %s to unprotect it first.", id, m.options.Keys.Binding(keymap.Protect).Help().Key)
+}
+
+// deleteAll deletes tasks in one transaction. When another process
+// protected one of them first nothing is deleted.
+func (m *model) deleteAll(tasks []*data.Task) error {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.Id
+	}
+	deleted, err := m.repository.DeleteTasks(ids, false)
+	var p *persistence.ProtectedError
+	if errors.As(err, &p) {
+		m.status = m.protectedStatus(p.Id)
+		m.refresh()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.status = fmt.Sprintf("Deleted %s.", plural(deleted, "task"))
+	m.list.ClearSelection()
+	m.refresh()
+	return nil
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func (p *prompt) Render(t theme.Theme, keys keymap.KeyMap) string {
+	title := t.Style(theme.Header)
+	help := t.Style(theme.Help)
+	subject := plural(len(p.tasks), "task")
+	switch p.kind {
+	case reschedulePrompt:
+		return title.Render("Reschedule "+subject) + "\n\n" +
+			help.Render(choices(keys, keymap.SnoozeToday, keymap.SnoozeTomorrow, keymap.SnoozeNextWeek, keymap.SnoozeNextMonth, keymap.PickDate, keymap.Cancel))
+	case deletePrompt:
+		return title.Render("Delete "+subject+"?") + "\n\n" + help.Render(choices(keys, keymap.Confirm, keymap.Decline, keymap.Cancel))
+	case datePrompt:
+		return title.Render("Move "+subject+" to") + "\n\n" + p.input.View() + "\n\n" +
+			help.Render("enter - Reschedule\nesc - Cancel")
+	default:
+		return title.Render("Tag "+subject) + "\n\n" + p.input.View() + "\n\n" +
+			help.Render("enter - Tag\nesc - Cancel")
+	}
+}
+
+// choices lists a prompt's actions one per line as their keys are bound:
+// "t - Today". Unbound actions are left out.
+func choices(keys keymap.KeyMap, actions ...keymap.Action) string {
+	var lines []string
+	for _, a := range actions {
+		b := keys.Binding(a)
+		if !b.Enabled() {
+			continue
+		}
+		desc := b.Help().Desc
+		lines = append(lines, b.Help().Key+" - "+strings.ToUpper(desc[:1])+desc[1:])
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,70 @@
+package list
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// conflict is a write the repository refused because another process
+// changed the task first. The list shows it as a prompt until the user
+// picks a version.
+type conflict struct {
+	mine   data.Task
+	theirs data.Task
+}
+
+// conflictFrom reports whether err is a version conflict on an update of
+// mine.
+func conflictFrom(err error, mine data.Task) (*conflict, bool) {
+	var c *persistence.ConflictError
+	if !errors.As(err, &c) {
+		return nil, false
+	}
+	return &conflict{mine: mine, theirs: c.Current}, true
+}
+
+// resolveConflict handles a key pressed while the conflict prompt is open:
+// Overwrite stores the list's copy over the stored task, KeepTheirs keeps
+// the stored one. Either way the list is reloaded.
+func (m *model) resolveConflict(k tea.KeyMsg) {
+	keys := m.options.Keys
+	switch {
+	case keys.Matches(k, keymap.Overwrite):
+		mine := m.conflict.mine
+		mine.Version = m.conflict.theirs.Version
+		err := m.repository.UpdateTask(mine)
+		if next, ok := conflictFrom(err, m.conflict.mine); ok {
+			m.conflict = next
+			return
+		}
+		if err != nil {
+			m.err = err
+		}
+	case keys.Matches(k, keymap.KeepTheirs):
+	default:
+		return
+	}
+	m.conflict = nil
+	m.refresh()
+}
+
+func (c conflict) Render(t theme.Theme, keys keymap.KeyMap) string {
+	label := t.Style(theme.Header)
+	rows := []string{
+		t.Style(theme.Error).Render(fmt.Sprintf("Task %d was changed elsewhere since the list loaded.", c.mine.Id)),
+		"",
+		label.Render("Theirs → yours"),
+	}
+	for _, change := range data.DiffTasks(c.theirs, c.mine) {
+		rows = append(rows, "  "+change.String())
+	}
+	rows = append(rows, "", t.Style(theme.Help).Render(choices(keys, keymap.Overwrite, keymap.KeepTheirs)))
+	return strings.Join(rows, "\n")
+}
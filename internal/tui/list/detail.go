@@ -0,0 +1,175 @@
+package list
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// sideBySideMinWidth is the narrowest terminal that fits the list and the
+	// detail pane next to each other; below it the pane moves under the list.
+	sideBySideMinWidth = 100
+	detailPaneRatio    = 0.4
+)
+
+type layout struct {
+	sideBySide  bool
+	listWidth   int
+	listHeight  int
+	detailWidth int
+}
+
+// newLayout splits the terminal between the list and the detail pane. When
+// stacked, the list gets half the height so the pane stays on screen.
+func newLayout(width int, height int) layout {
+	if width <= 0 {
+		return layout{}
+	}
+	if width < sideBySideMinWidth {
+		return layout{listWidth: width, listHeight: height / 2, detailWidth: width}
+	}
+	detail := int(float64(width) * detailPaneRatio)
+	return layout{sideBySide: true, listWidth: width - detail, detailWidth: detail}
+}
+
+func (l layout) join(list string, detail string) string {
+	if l.sideBySide {
+		return lipgloss.JoinHorizontal(lipgloss.Top, list, detail)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, list, detail)
+}
+
+type detailTab int
+
+const (
+	detailsTab detailTab = iota
+	historyTab
+)
+
+func (t detailTab) next() detailTab {
+	return (t + 1) % 2
+}
+
+type detailComponent struct {
+	theme theme.Theme
+	width int
+	now   time.Time
+	deps  data.Dependencies
+}
+
+func (d detailComponent) Render(task data.Task, tab detailTab, history []data.Event) string {
+	var body string
+	if tab == historyTab {
+		body = d.history(history)
+	} else {
+		body = d.details(task)
+	}
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(d.theme.Style(theme.Help).GetForeground()).
+		Padding(0, 1)
+	if d.width > 0 {
+		style = style.Width(d.width - style.GetHorizontalBorderSize())
+	}
+	return style.Render(d.tabs(tab) + "\n\n" + body)
+}
+
+func (d detailComponent) tabs(active detailTab) string {
+	names := []string{"Details", "History"}
+	for i, name := range names {
+		if detailTab(i) == active {
+			names[i] = d.theme.Style(theme.Header).Underline(true).Render(name)
+		} else {
+			names[i] = d.theme.Style(theme.Done).Render(name)
+		}
+	}
+	return strings.Join(names, " | ")
+}
+
+func (d detailComponent) history(events []data.Event) string {
+	if len(events) == 0 {
+		return d.theme.Style(theme.Done).Render("No recorded changes")
+	}
+	label := d.theme.Style(theme.Header)
+	var rows []string
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		rows = append(rows, label.Render(event.At.Local().Format(data.TimestampLayout)+" "+string(event.Kind)))
+		for _, change := range event.Changes {
+			rows = append(rows, "  "+change.String())
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (d detailComponent) details(task data.Task) string {
+	label := d.theme.Style(theme.Header)
+	row := func(name string, value string) string {
+		return label.Render(fmt.Sprintf("%-9s", name)) + " " + value
+	}
+
+	rows := []string{
+		lipgloss.NewStyle().Bold(true).Render(task.Title),
+		"",
+		row("Status", status(task)),
+	}
+	if !task.DueDate.IsZero() {
+		role := theme.UrgencyRole(data.TaskUrgency(task, d.now))
+		due := fmt.Sprintf("%s (%s)", task.DueDate.Format(time.DateOnly), data.RelativeDue(task.DueDate, d.now))
+		rows = append(rows, row("Due", d.theme.Style(role).Render(due)))
+	}
+	if task.Priority != "" {
+		rows = append(rows, row("Priority", task.Priority))
+	}
+	if task.Project != "" {
+		rows = append(rows, row("Project", task.Project))
+	}
+	if len(task.Tags) > 0 {
+		rows = append(rows, row("Tags", strings.Join(task.Tags, ", ")))
+	}
+	if task.ParentId != 0 {
+		rows = append(rows, row("Subtask", fmt.Sprintf("of task %d", task.ParentId)))
+	}
+	if blockers := d.deps.BlockedBy(task.Id); len(blockers) > 0 {
+		rows = append(rows, row("Blockers", data.JoinIds(blockers)))
+	}
+	if blocked := d.deps.Blocking(task.Id); len(blocked) > 0 {
+		rows = append(rows, row("Blocks", data.JoinIds(blocked)))
+	}
+	if !task.CreatedAt.IsZero() {
+		rows = append(rows, row("Created", task.CreatedAt.Local().Format(data.TimestampLayout)))
+	}
+	if !task.UpdatedAt.IsZero() {
+		rows = append(rows, row("Updated", task.UpdatedAt.Local().Format(data.TimestampLayout)))
+	}
+	if !task.CompletedAt.IsZero() {
+		rows = append(rows, row("Completed", task.CompletedAt.Local().Format(data.TimestampLayout)))
+	}
+	if task.Notes != "" {
+		rows = append(rows, "", label.Render("Notes"), task.Notes)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// status names the task's workflow status, falling back to open or complete
+// for tasks that have none.
+func status(task data.Task) string {
+	s := task.Status
+	switch {
+	case s != "":
+	case task.Complete:
+		s = "complete"
+	default:
+		s = "open"
+	}
+	if task.Protected {
+		s += ", protected"
+	}
+	return s
+}
@@ -2,11 +2,58 @@ package list
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
+	"github.com/ake3mio/go-todo-cli/internal/data"
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 )
 
-func NewList(repository persistence.TodoRepository) *tui.Runner {
-	return tui.NewRunner(context.Background(), createModel(repository))
+// Options configures how the list view orders and groups tasks.
+type Options struct {
+	Sort    data.SortKey
+	Desc    bool
+	GroupBy data.GroupBy
+	Theme   theme.Theme
+	Keys    keymap.KeyMap
+	// ConfirmDelete says when deleting asks first; the zero value always
+	// does.
+	ConfirmDelete ConfirmDelete
+}
+
+// ConfirmDelete says which deletes the list view asks to confirm.
+type ConfirmDelete string
+
+const (
+	ConfirmAlways     ConfirmDelete = "always"
+	ConfirmNever      ConfirmDelete = "never"
+	ConfirmIncomplete ConfirmDelete = "incomplete"
+)
+
+func ParseConfirmDelete(s string) (ConfirmDelete, error) {
+	switch c := ConfirmDelete(s); c {
+	case "":
+		return ConfirmAlways, nil
+	case ConfirmAlways, ConfirmNever, ConfirmIncomplete:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid confirm_delete %q: use always, never or incomplete", s)
+}
+
+// needsConfirm reports whether deleting tasks asks first.
+func (c ConfirmDelete) needsConfirm(tasks []*data.Task) bool {
+	switch c {
+	case ConfirmNever:
+		return false
+	case ConfirmIncomplete:
+		return slices.ContainsFunc(tasks, func(task *data.Task) bool { return !task.Complete })
+	}
+	return true
+}
+
+func NewList(repository persistence.TodoRepository, options Options) *tui.Runner {
+	return tui.NewRunner(context.Background(), createModel(repository, options))
 }
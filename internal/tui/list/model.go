@@ -1,125 +1,244 @@
 package list
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/ake3mio/go-todo-cli/internal/data"
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type model struct {
-	form                  *huh.Form
-	tasks                 []data.Task
-	selectedIDs           []string
-	lastSelected          map[int]bool
-	hideCompleted         bool
-	suppressNextReconcile bool
-	repository            persistence.TodoRepository
-	err                   error
-	ms                    *huh.MultiSelect[string]
-	next                  tui.Command
-	once                  sync.Once
+	list  *tui.TaskList
+	tasks map[int]*data.Task
+	// dirty holds the ids of tasks the list changed but has not stored.
+	dirty         map[int]bool
+	hideCompleted bool
+	repository    persistence.TodoRepository
+	err           error
+	version       int64
+	conflict      *conflict
+	prompt        *prompt
+	status        string
+	options       Options
+	width         int
+	height        int
+	detailTab     detailTab
+	showHelp      bool
+	history       map[int][]data.Event
+	// deps are the links between tasks, for the blocked and blocking
+	// markers.
+	deps data.Dependencies
+	next tui.Command
+	once sync.Once
+	// now is the clock due dates are shown and rescheduled against.
+	now func() time.Time
+}
+
+// pageSize is how many tasks the list reads at a time when it is not
+// grouped. Grouping needs every task, so grouped lists read them all at once.
+const pageSize = 200
+
+// helpHeight is how many lines the shortcut help below the list takes.
+const helpHeight = 3
+
+// refreshInterval is how often the list checks whether another process has
+// changed the database.
+const refreshInterval = time.Second
+
+type pollMsg struct{}
+
+func poll() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return pollMsg{} })
 }
 
 func (m *model) Init() tea.Cmd {
-	return m.form.Init()
+	return tea.Batch(tea.WindowSize(), poll())
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	fm, cmd := m.form.Update(msg)
-	if f, ok := fm.(*huh.Form); ok {
-		m.form = f
+	if _, ok := msg.(pollMsg); ok {
+		if m.conflict == nil {
+			m.refreshIfChanged()
+		}
+		return m, poll()
+	}
+	if k, ok := msg.(tea.KeyMsg); ok && m.conflict != nil {
+		m.resolveConflict(k)
+		return m, nil
+	}
+	if _, ok := msg.(tea.KeyMsg); ok && m.showHelp {
+		m.showHelp = false
+		return m, nil
+	}
+	if k, ok := msg.(tea.KeyMsg); ok && m.prompt != nil {
+		if err := m.updatePrompt(k); err != nil {
+			m.err = err
+		}
+		return m, nil
 	}
 
-	if k, ok := msg.(tea.KeyMsg); ok {
-		switch k.String() {
-		case "ctrl+h":
-			m.hideCompleted = !m.hideCompleted
-			m.suppressNextReconcile = true
-			return m, m.updateWithNewForm()
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = size.Width
+		m.height = size.Height
+		m.applyLayout()
+	}
 
-		case "ctrl+a":
-			m.next = tui.AddTask
-			return m, m.cleanupAndQuit()
+	if err, ok := msg.(error); ok {
+		m.err = err
+		return m, nil
+	}
 
-		case "delete", "backspace":
-			if id, ok := m.ms.Hovered(); ok {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.status = ""
+	keys := m.options.Keys
+	switch {
+	case keys.Matches(k, keymap.HideCompleted):
+		m.hideCompleted = !m.hideCompleted
+		m.refresh()
+		return m, nil
 
-				if err := m.deleteTaskById(id); err != nil {
-					m.err = err
-					return m, nil
-				}
+	case keys.Matches(k, keymap.AddTask):
+		m.next = tui.AddTask
+		return m, m.cleanupAndQuit()
 
-				return m, m.updateWithNewForm()
-			}
-		}
+	case keys.Matches(k, keymap.Calendar):
+		m.next = tui.Calendar
+		return m, m.cleanupAndQuit()
+
+	case keys.Matches(k, keymap.Board):
+		m.next = tui.Board
+		return m, m.cleanupAndQuit()
 
-		quitCmd := tui.Quit(k.String(), m.Cleanup)
-		if quitCmd != nil {
-			var _ = m.saveAll()
-			return m, quitCmd
+	case keys.Matches(k, keymap.DetailTab):
+		m.detailTab = m.detailTab.next()
+
+	case keys.Matches(k, keymap.Reschedule):
+		if tasks := m.list.Targets(); len(tasks) > 0 {
+			m.prompt = newPrompt(reschedulePrompt, tasks)
 		}
-	}
+		return m, nil
 
-	if !m.suppressNextReconcile {
-		var err error
+	case keys.Matches(k, keymap.Tag):
+		if tasks := m.list.Targets(); len(tasks) > 0 {
+			m.prompt = newPrompt(tagPrompt, tasks)
+		}
+		return m, nil
 
-		if err = m.applyAndSaveToggles(); err != nil {
+	case keys.Matches(k, keymap.RollOver):
+		if err := m.rollOver(); err != nil {
 			m.err = err
 		}
-	} else {
-		m.suppressNextReconcile = false
-	}
+		return m, nil
 
-	if err, ok := msg.(error); ok {
-		m.err = err
+	case keys.Matches(k, keymap.Help):
+		m.showHelp = true
 		return m, nil
-	}
 
-	if m.form.State == huh.StateCompleted || m.form.State == huh.StateAborted {
-		var _ = m.saveAll()
+	case keys.Matches(k, keymap.Done):
 		return m, m.cleanupAndQuit()
 	}
 
+	if quitCmd := tui.Quit(keys, k, m.Cleanup); quitCmd != nil {
+		return m, quitCmd
+	}
+
+	cmd := m.list.Update(msg)
+	if err := m.loadHoveredHistory(); err != nil {
+		m.err = err
+	}
 	return m, cmd
 }
 
 func (m *model) View() string {
 	if m.err != nil {
-		component := tui.ErrorComponent{}
+		component := tui.ErrorComponent{Theme: m.options.Theme}
 		return component.Render(m)
 	}
 
+	if m.conflict != nil {
+		return lipgloss.NewStyle().Padding(1).Render(m.conflict.Render(m.options.Theme, m.options.Keys))
+	}
+
+	if m.prompt != nil {
+		return lipgloss.NewStyle().Padding(1).Render(m.prompt.Render(m.options.Theme, m.options.Keys) + m.statusLine())
+	}
+
+	if m.showHelp {
+		return lipgloss.NewStyle().Padding(1).Render(m.helpView())
+	}
+
 	if len(m.tasks) == 0 {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("2")).
+		return m.options.Theme.Style(theme.Empty).
 			Padding(1).
-			Render("Press ctrl+a to add a new task.")
+			Render(fmt.Sprintf("Press %s to add a new task.", m.options.Keys.Binding(keymap.AddTask).Help().Key))
 	}
 
-	return m.form.View() + lipgloss.NewStyle().
-		Foreground(lipgloss.Color("3")).
-		Padding(1).
-		Render(`
+	l := newLayout(m.width, m.height)
+	body := lipgloss.NewStyle().Padding(1, 0).MaxWidth(max(l.listWidth, 0)).Render(m.list.View())
+	if l.listWidth == 0 {
+		body = lipgloss.NewStyle().Padding(1, 0).Render(m.list.View())
+	}
+	if task, ok := m.hoveredTask(); ok {
+		detail := detailComponent{theme: m.options.Theme, width: l.detailWidth, now: m.now(), deps: m.deps}
+		body = l.join(body, detail.Render(task, m.detailTab, m.history[task.Id]))
+	}
 
-Special Shortcuts:
-ctrl + h - Toggle hiding completed tasks
-ctrl + a - Add a new task
-delete/backspace - Delete a selected task
-q/ctrl + c/esc - Quit
-`)
+	help := m.options.Keys.ShortHelp(m.options.Theme, max(m.width-2, 0),
+		keymap.Help, keymap.Toggle, keymap.Select, keymap.Delete, keymap.AddTask, keymap.HideCompleted, keymap.Quit)
+	return body + m.statusLine() + "\n" + lipgloss.NewStyle().Padding(0, 1).Render(help) + "\n"
+}
+
+// promptHelp titles the prompts whose keys the help overlay lists after
+// the list's own.
+var promptHelp = []struct {
+	title string
+	view  keymap.View
+}{
+	{"Reschedule", keymap.RescheduleView},
+	{"Confirm delete", keymap.ConfirmView},
+	{"Conflict", keymap.ConflictView},
+}
+
+func (m *model) helpView() string {
+	t, keys := m.options.Theme, m.options.Keys
+	out := t.Style(theme.Header).Render("Keys") + "\n\n" + keys.FullHelp(t, keymap.ListView)
+	for _, p := range promptHelp {
+		out += "\n\n" + t.Style(theme.Header).Render(p.title) + "\n" + keys.FullHelp(t, p.view)
+	}
+	return out + "\n\n" + t.Style(theme.Help).Render("Press any key to close.")
+}
+
+// statusLine reports the outcome of the last bulk action.
+func (m *model) statusLine() string {
+	if m.status == "" {
+		return ""
+	}
+	return "\n" + m.options.Theme.Style(theme.Empty).Padding(0, 1).Render(m.status)
 }
 
+// Cleanup stores the tasks with unsaved changes and closes the repository.
+// Every way out of the list goes through it, so a save that fails is
+// reported when the program exits.
 func (m *model) Cleanup() {
 	m.once.Do(func() {
-		if err := m.repository.Close(); err != nil {
+		err := m.saveDirty()
+		if closeErr := m.repository.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
 			m.err = err
 		}
 	})
@@ -130,120 +249,248 @@ func (m *model) cleanupAndQuit() tea.Cmd {
 	return tea.Quit
 }
 
-func (m *model) updateWithNewForm() tea.Cmd {
-	createNewTaskListForm(m)
-	return tea.Sequence(
-		m.form.Init(),
-		tea.Tick(0, func(time.Time) tea.Msg { return tea.KeyMsg{Type: tea.KeyHome} }),
-	)
-}
 func (m *model) Err() error { return m.err }
 
 func (m *model) Next() tui.Command { return m.next }
 
-func createModel(repo persistence.TodoRepository) *model {
+func createModel(repo persistence.TodoRepository, options Options) *model {
 	m := &model{
-		repository:   repo,
-		selectedIDs:  []string{},
-		lastSelected: map[int]bool{},
-		next:         tui.NoneTask,
+		repository: repo,
+		options:    options,
+		dirty:      map[int]bool{},
+		next:       tui.NoneTask,
+		now:        time.Now,
+	}
+	m.list = tui.NewTaskList(options.Theme, options.Keys)
+	m.list.Label = func(task data.Task) string { return taskLabel(task, m.deps, options.Theme, m.now()) }
+	m.list.Actions = []tui.TaskAction{
+		{Action: keymap.Toggle, Do: m.toggle, Bulk: m.completeAll},
+		{Action: keymap.Delete, Do: m.deleteOne, Bulk: m.requestDelete},
+		{Action: keymap.Protect, Do: func(task *data.Task) error { return m.protectAll([]*data.Task{task}) }, Bulk: m.protectAll},
+	}
+	m.err = createNewTaskList(m)
+	if m.err == nil {
+		m.version, m.err = repo.DataVersion()
 	}
-	createNewTaskListForm(m)
 	return m
 }
 
-func createNewTaskListForm(m *model) {
-	tasks, err := m.repository.GetTasks()
-	if err != nil {
-		panic(err)
+// createNewTaskList loads the list from the repository, reading its first
+// page.
+func createNewTaskList(m *model) error {
+	return m.reload(m.list.Reset)
+}
+
+// reload rereads the tasks through load, which is m.list.Reset or
+// m.list.Reload.
+func (m *model) reload(load func(tui.PageLoader[tui.TaskRow]) error) error {
+	// Reloading replaces the list's copies, so store any unsaved changes
+	// first; whatever still fails to save is dropped with them.
+	err := m.saveDirty()
+	clear(m.dirty)
+
+	m.tasks = map[int]*data.Task{}
+	m.history = make(map[int][]data.Event)
+	m.applyLayout()
+	deps, depsErr := m.repository.GetDependencies()
+	if depsErr != nil {
+		return depsErr
+	}
+	m.deps = deps
+	if loadErr := load(m.loader()); loadErr != nil {
+		return loadErr
 	}
-	m.tasks = tasks
-	opts := make([]huh.Option[string], 0, len(m.tasks))
-	m.lastSelected = make(map[int]bool)
-	m.selectedIDs = make([]string, 0)
+	return err
+}
 
-	for _, task := range m.tasks {
-		if m.hideCompleted && task.Complete {
-			continue
-		}
-		m.lastSelected[task.Id] = task.Complete
-		label := fmt.Sprintf("%s ~ due %s", task.Title, task.DueDate.Format(time.DateOnly))
-		idStr := strconv.Itoa(task.Id)
-		opts = append(opts, huh.NewOption(idStr+" - "+label, idStr))
-		if task.Complete {
-			m.selectedIDs = append(m.selectedIDs, idStr)
+// loader reads the list's rows. Ungrouped lists are read a page at a time;
+// grouped ones all at once, since any task may belong to the first group.
+func (m *model) loader() tui.PageLoader[tui.TaskRow] {
+	opts := persistence.ListOptions{Sort: m.options.Sort, Desc: m.options.Desc, HideCompleted: m.hideCompleted}
+	if m.options.GroupBy != data.GroupByNone && m.options.GroupBy != "" {
+		return func() ([]tui.TaskRow, bool, error) {
+			tasks, err := m.repository.FindTasks(opts)
+			if err != nil {
+				return nil, false, err
+			}
+			// A task can sit in several groups; its rows share one copy.
+			for i := range tasks {
+				m.tasks[tasks[i].Id] = &tasks[i]
+			}
+			var rows []tui.TaskRow
+			for _, group := range data.GroupTasks(tasks, m.options.GroupBy, m.now()) {
+				rows = append(rows, tui.TaskRow{Heading: group.Name})
+				for _, task := range group.Tasks {
+					rows = append(rows, tui.TaskRow{Task: m.tasks[task.Id]})
+				}
+			}
+			return rows, false, nil
 		}
 	}
 
-	ms := huh.NewMultiSelect[string]().
-		Title("Tasks").
-		Options(opts...).
-		Value(&m.selectedIDs).
-		Filtering(false).
-		Filterable(false)
-
-	m.ms = ms
+	opts.Limit = pageSize
+	return func() ([]tui.TaskRow, bool, error) {
+		tasks, err := m.repository.FindTasks(opts)
+		if err != nil {
+			return nil, false, err
+		}
+		var rows []tui.TaskRow
+		if opts.After == nil {
+			rows = append(rows, tui.TaskRow{Heading: "Tasks"})
+		}
+		for i := range tasks {
+			m.tasks[tasks[i].Id] = &tasks[i]
+			rows = append(rows, tui.TaskRow{Task: &tasks[i]})
+		}
+		if len(tasks) > 0 {
+			last := tasks[len(tasks)-1]
+			opts.After = &last
+		}
+		return rows, len(tasks) == pageSize, nil
+	}
+}
 
-	form := huh.NewForm(huh.NewGroup(ms))
-	m.form = form
+// taskLabel renders a task as its title, marked when protected and with the
+// open tasks it waits on or holds up, followed by the due date relative to
+// now, coloured by urgency, with the calendar date alongside.
+func taskLabel(task data.Task, deps data.Dependencies, t theme.Theme, now time.Time) string {
+	title := task.Title
+	if task.Protected {
+		title += " (protected)"
+	}
+	if blockers := deps.BlockedBy(task.Id); len(blockers) > 0 && !task.Complete {
+		title += " " + t.Style(theme.Overdue).Render("(blocked by "+data.JoinIds(blockers)+")")
+	}
+	if blocked := deps.Blocking(task.Id); len(blocked) > 0 {
+		title += " " + t.Style(theme.Soon).Render("(blocks "+data.JoinIds(blocked)+")")
+	}
+	if task.DueDate.IsZero() {
+		return title
+	}
+	due := fmt.Sprintf("%s (%s)", data.RelativeDue(task.DueDate, now), task.DueDate.Format(time.DateOnly))
+	role := theme.UrgencyRole(data.TaskUrgency(task, now))
+	return fmt.Sprintf("%s ~ %s", title, t.Style(role).Render(due))
+}
 
+// applyLayout fits the list to its column, leaving room for the help below
+// it and, when stacked, the detail pane.
+func (m *model) applyLayout() {
+	l := newLayout(m.width, m.height)
+	switch {
+	case l.listHeight > 0:
+		m.list.SetHeight(l.listHeight - 2)
+	case m.height > 0:
+		m.list.SetHeight(m.height - helpHeight - 2)
+	}
 }
 
-func (m *model) applyAndSaveToggles() error {
+// loadHoveredHistory fetches the hovered task's history once the history tab
+// is showing. Results are cached until the tasks change.
+func (m *model) loadHoveredHistory() error {
+	if m.detailTab != historyTab {
+		return nil
+	}
+	task, ok := m.hoveredTask()
+	if !ok {
+		return nil
+	}
+	if _, cached := m.history[task.Id]; cached {
+		return nil
+	}
+	events, err := m.repository.GetTaskHistory(task.Id)
+	if err != nil {
+		return err
+	}
+	m.history[task.Id] = events
+	return nil
+}
 
-	curr := make(map[int]bool, len(m.selectedIDs))
-	for _, s := range m.selectedIDs {
-		if id, err := strconv.Atoi(s); err == nil {
-			curr[id] = true
-		}
+// refreshIfChanged reloads the list when the database has changed since it
+// was last read, so tasks added or edited elsewhere show up.
+func (m *model) refreshIfChanged() {
+	version, err := m.repository.DataVersion()
+	if err != nil {
+		m.err = err
+		return
+	}
+	if version == m.version {
+		return
 	}
+	m.version = version
+	m.refresh()
+}
 
-	var firstErr error
-	for i := range m.tasks {
-		id := m.tasks[i].Id
-		shouldBe := curr[id]
-		was := m.lastSelected[id]
-		if shouldBe != was {
-			m.tasks[i].Complete = shouldBe
-			if err := m.repository.UpdateTask(m.tasks[i]); err != nil && firstErr == nil {
-				firstErr = err
-			}
-			m.lastSelected[id] = shouldBe
-		}
+// refresh reloads the list, keeping the cursor on the task it was on.
+func (m *model) refresh() {
+	if err := m.reload(m.list.Reload); err != nil {
+		m.err = err
 	}
-	return firstErr
 }
 
-func (m *model) saveAll() error {
-	_ = m.applyAndSaveToggles()
-	return m.repository.UpdateTasks(m.tasks)
+func (m *model) hoveredTask() (data.Task, bool) {
+	task, ok := m.list.Current()
+	if !ok {
+		return data.Task{}, false
+	}
+	return *task, true
 }
 
-func (m *model) deleteTaskById(id string) error {
-	if val, err := strconv.Atoi(id); err == nil {
-		out := m.tasks[:0]
-		for _, task := range m.tasks {
-			if task.Id != val {
-				out = append(out, task)
-			}
-		}
-		m.tasks = out
+// toggle flips task between complete and open and saves it.
+func (m *model) toggle(task *data.Task) error {
+	task.Complete = !task.Complete
+	m.dirty[task.Id] = true
+	delete(m.history, task.Id)
+	return m.saveToggle(task)
+}
 
-		newSelected := m.selectedIDs[:0]
-		for _, selected := range m.selectedIDs {
-			if selected != id {
-				newSelected = append(newSelected, selected)
-			}
+// saveToggle stores task. A write that conflicts with another process opens
+// the conflict prompt instead of failing, and completing a task that waits
+// on open ones is undone with a message.
+func (m *model) saveToggle(task *data.Task) error {
+	err := m.repository.UpdateTask(*task)
+	var b *persistence.BlockedError
+	if errors.As(err, &b) {
+		task.Complete = false
+		delete(m.dirty, task.Id)
+		m.status = tui.BlockedStatus(b)
+		return nil
+	}
+	if c, ok := conflictFrom(err, *task); ok {
+		if m.conflict == nil {
+			m.conflict = c
 		}
-		m.selectedIDs = newSelected
-
-		delete(m.lastSelected, val)
+		delete(m.dirty, task.Id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	delete(m.dirty, task.Id)
+	// Pick up the new version for the task's next update.
+	stored, err := m.repository.GetTask(task.Id)
+	if err != nil {
+		return err
+	}
+	*task = stored
+	return nil
+}
 
-		if err = m.repository.DeleteTaskById(val); err != nil {
-			m.err = err
-			return err
+// saveDirty stores every task with unsaved changes, such as toggles that
+// failed to save, in one batch. Tasks the list has not changed are not
+// written.
+func (m *model) saveDirty() error {
+	var tasks []data.Task
+	for _, id := range slices.Sorted(maps.Keys(m.dirty)) {
+		if task, ok := m.tasks[id]; ok {
+			tasks = append(tasks, *task)
 		}
 	}
-
+	if len(tasks) == 0 {
+		return nil
+	}
+	if err := m.repository.UpdateTasks(tasks); err != nil {
+		return err
+	}
+	clear(m.dirty)
 	return nil
 }
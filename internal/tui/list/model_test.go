@@ -2,25 +2,52 @@ package list
 
 import (
 	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ake3mio/go-todo-cli/internal/data"
 	"github.com/ake3mio/go-todo-cli/internal/persistence"
 	"github.com/ake3mio/go-todo-cli/internal/tui"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+var errClosed = errors.New("sql: database is closed")
+
 type fakeRepo struct {
 	tasks            []data.Task
+	history          map[int][]data.Event
+	historyCalls     []int
 	updateTaskCalls  []data.Task
-	updateTasksCalls int
+	updateTasksCalls [][]data.Task
+	failUpdate       error
 	deletes          []int
+	deleteTasksCalls [][]int
+	version          int64
+	findCalls        int
+	deps             data.Dependencies
+	// closed makes writes fail, as they do on a closed database.
+	closed bool
 }
 
-func (r *fakeRepo) Close() error                                  { return nil }
+func (r *fakeRepo) Close() error                                  { r.closed = true; return nil }
 func (r *fakeRepo) SaveTask(task string, dueDate time.Time) error { return nil }
+func (r *fakeRepo) CreateTask(task data.Task) (int, error)        { return 0, nil }
+
+func (r *fakeRepo) GetTask(id int) (data.Task, error) {
+	for _, t := range r.tasks {
+		if t.Id == id {
+			return t, nil
+		}
+	}
+	return data.Task{}, persistence.ErrTaskNotFound
+}
 
 func (r *fakeRepo) GetTasks() ([]data.Task, error) {
 	cp := make([]data.Task, len(r.tasks))
@@ -28,9 +55,49 @@ func (r *fakeRepo) GetTasks() ([]data.Task, error) {
 	return cp, nil
 }
 
+// FindTasks keeps the tasks' order, honouring the filter and paging options.
+func (r *fakeRepo) FindTasks(opts persistence.ListOptions) ([]data.Task, error) {
+	r.findCalls++
+	var out []data.Task
+	started := opts.After == nil
+	for _, t := range r.tasks {
+		if !started {
+			started = t.Id == opts.After.Id
+			continue
+		}
+		if opts.HideCompleted && t.Complete {
+			continue
+		}
+		if opts.Limit > 0 && len(out) == opts.Limit {
+			break
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) GetCompletedTasks(since time.Time) ([]data.Task, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetTaskHistory(id int) ([]data.Event, error) {
+	r.historyCalls = append(r.historyCalls, id)
+	return r.history[id], nil
+}
+
 func (r *fakeRepo) UpdateTask(t data.Task) error {
+	if r.closed {
+		return errClosed
+	}
+	if r.failUpdate != nil {
+		return r.failUpdate
+	}
 	for i := range r.tasks {
 		if r.tasks[i].Id == t.Id {
+			if t.Version != 0 && t.Version != r.tasks[i].Version {
+				return &persistence.ConflictError{Id: t.Id, Current: r.tasks[i]}
+			}
+			t.Version = r.tasks[i].Version + 1
 			r.tasks[i] = t
 			break
 		}
@@ -39,14 +106,34 @@ func (r *fakeRepo) UpdateTask(t data.Task) error {
 	return nil
 }
 
+// UpdateTasks writes all of ts or, like the real repository, none of them.
 func (r *fakeRepo) UpdateTasks(ts []data.Task) error {
-	cp := make([]data.Task, len(ts))
-	copy(cp, ts)
-	r.tasks = cp
-	r.updateTasksCalls++
+	if r.closed {
+		return errClosed
+	}
+	if r.failUpdate != nil {
+		return r.failUpdate
+	}
+	for _, t := range ts {
+		for i := range r.tasks {
+			if r.tasks[i].Id == t.Id && t.Version != 0 && t.Version != r.tasks[i].Version {
+				return &persistence.ConflictError{Id: t.Id, Current: r.tasks[i]}
+			}
+		}
+	}
+	r.updateTasksCalls = append(r.updateTasksCalls, ts)
+	for _, t := range ts {
+		for i := range r.tasks {
+			if r.tasks[i].Id == t.Id {
+				t.Version = r.tasks[i].Version + 1
+				r.tasks[i] = t
+			}
+		}
+	}
 	return nil
 }
 
+func (r *fakeRepo) UpsertTasks(tasks []data.Task) ([]int, error) { return nil, nil }
 func (r *fakeRepo) DeleteTaskById(id int) error {
 	newSlice := r.tasks[:0]
 	for _, t := range r.tasks {
@@ -59,6 +146,37 @@ func (r *fakeRepo) DeleteTaskById(id int) error {
 	return nil
 }
 
+func (r *fakeRepo) DeleteTasks(ids []int, force bool) (int, error) {
+	r.deleteTasksCalls = append(r.deleteTasksCalls, ids)
+	for _, task := range r.tasks {
+		if !force && task.Protected && slices.Contains(ids, task.Id) {
+			return 0, &persistence.ProtectedError{Id: task.Id}
+		}
+	}
+	before := len(r.tasks)
+	r.tasks = slices.DeleteFunc(r.tasks, func(t data.Task) bool { return slices.Contains(ids, t.Id) })
+	return before - len(r.tasks), nil
+}
+
+func (r *fakeRepo) DataVersion() (int64, error) { return r.version, nil }
+
+// GetDependencies returns deps with each task's completion filled in.
+func (r *fakeRepo) GetDependencies() ([]data.Dependency, error) {
+	deps := slices.Clone(r.deps)
+	for i := range deps {
+		blocker, _ := r.GetTask(deps[i].Blocker)
+		blocked, _ := r.GetTask(deps[i].Blocked)
+		deps[i].BlockerComplete, deps[i].BlockedComplete = blocker.Complete, blocked.Complete
+	}
+	return deps, nil
+}
+func (r *fakeRepo) LinkTasks(blocker int, blocked []int) error   { return nil }
+func (r *fakeRepo) UnlinkTasks(blocker int, blocked []int) error { return nil }
+func (r *fakeRepo) GetStatuses() ([]data.Status, error) {
+	return nil, nil
+}
+func (r *fakeRepo) SetStatuses(statuses []data.Status) error { return nil }
+
 func newFakeRepo() (persistence.TodoRepository, *fakeRepo) {
 	repo := &fakeRepo{
 		tasks: []data.Task{
@@ -74,6 +192,10 @@ func sendKey(m tea.Model, key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "ctrl+h":
 		return m.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	case "ctrl+t":
+		return m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	case "ctrl+a":
+		return m.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
 	case "delete":
 		return m.Update(tea.KeyMsg{Type: tea.KeyDelete})
 	case "backspace":
@@ -83,6 +205,22 @@ func sendKey(m tea.Model, key string) (tea.Model, tea.Cmd) {
 	}
 }
 
+func hoveredId(m *model) int {
+	task, _ := m.hoveredTask()
+	return task.Id
+}
+
+func completeIds(m *model) []int {
+	var ids []int
+	for _, task := range m.tasks {
+		if task.Complete {
+			ids = append(ids, task.Id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
 func drain(cmd tea.Cmd) {
 	if cmd == nil {
 		return
@@ -92,22 +230,21 @@ func drain(cmd tea.Cmd) {
 
 func TestModel_InitialState(t *testing.T) {
 	tr, _ := newFakeRepo()
-	m := createModel(tr)
-
-	assert.NotNil(t, m.form)
-	assert.NotNil(t, m.ms)
+	m := createModel(tr, Options{})
 
-	assert.Equal(t, map[int]bool{
-		1: false,
-		2: true,
-	}, m.lastSelected)
+	assert.Len(t, m.tasks, 2)
+	assert.Equal(t, []int{2}, completeIds(m))
+	assert.Equal(t, 1, hoveredId(m))
 
-	assert.ElementsMatch(t, []string{"2"}, m.selectedIDs)
+	out := m.View()
+	assert.Contains(t, out, "Tasks")
+	assert.Contains(t, out, "[ ] 1 - A")
+	assert.Contains(t, out, "[x] 2 - B")
 }
 
 func TestModel_ToggleHideCompletedWithCtrlH(t *testing.T) {
 	tr, _ := newFakeRepo()
-	m := createModel(tr)
+	m := createModel(tr, Options{})
 
 	upd, cmd := sendKey(m, "ctrl+h")
 	drain(cmd)
@@ -115,32 +252,34 @@ func TestModel_ToggleHideCompletedWithCtrlH(t *testing.T) {
 
 	assert.True(t, got.hideCompleted, "hideCompleted should be true after toggle")
 
-	assert.NotContains(t, got.selectedIDs, "2")
+	assert.Len(t, got.tasks, 1)
+	assert.Empty(t, completeIds(got))
 }
 
 func TestModel_DeleteHovered_RemovesFirstItem(t *testing.T) {
 	tr, fr := newFakeRepo()
-	m := createModel(tr)
+	m := createModel(tr, Options{})
 
 	upd, cmd := sendKey(m, "delete")
 	drain(cmd)
+	assert.Contains(t, m.View(), "Delete 1 task?")
+	assert.Empty(t, fr.deleteTasksCalls, "deleting asks first")
+	upd, cmd = sendKey(upd, "y")
+	drain(cmd)
 	got := upd.(*model)
 
-	assert.Contains(t, fr.deletes, 1)
+	assert.Equal(t, [][]int{{1}}, fr.deleteTasksCalls)
 
 	for _, tsk := range got.tasks {
 		assert.NotEqual(t, 1, tsk.Id)
 	}
 }
 
-func TestModel_Reconcile_ToggleSelection_PersistsImmediately(t *testing.T) {
+func TestModel_Toggle_PersistsImmediately(t *testing.T) {
 	tr, fr := newFakeRepo()
-	m := createModel(tr)
-
-	m.selectedIDs = append(m.selectedIDs, "1")
-	m.lastSelected[1] = false
+	m := createModel(tr, Options{})
 
-	upd, cmd := m.Update(struct{}{})
+	upd, cmd := sendKey(m, "x")
 	assert.NotNil(t, upd)
 	assert.Nil(t, cmd)
 
@@ -148,12 +287,313 @@ func TestModel_Reconcile_ToggleSelection_PersistsImmediately(t *testing.T) {
 	assert.Equal(t, 1, fr.updateTaskCalls[0].Id)
 	assert.True(t, fr.updateTaskCalls[0].Complete)
 
-	assert.True(t, m.lastSelected[1])
+	assert.ElementsMatch(t, []int{1, 2}, completeIds(m))
+}
+
+func TestModel_Select_LeavesCompletionAlone(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{})
+
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	sendKey(m, "j")
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+
+	assert.Equal(t, []int{1, 2}, m.list.Selected())
+	assert.Equal(t, []int{2}, completeIds(m))
+	assert.Empty(t, fr.updateTaskCalls, "selecting writes nothing")
+	assert.Contains(t, m.View(), "* [ ] 1 - A")
+
+	fr.version++
+	m.Update(pollMsg{})
+	assert.Equal(t, []int{1, 2}, m.list.Selected(), "the selection survives reloads")
+
+	sendKey(m, "delete")
+	assert.Contains(t, m.View(), "Delete 2 tasks?")
+	sendKey(m, "y")
+	assert.Equal(t, [][]int{{1, 2}}, fr.deleteTasksCalls, "with tasks selected, delete applies to them all")
+	assert.Empty(t, m.list.Selected())
+}
+
+func TestModel_ConfirmDelete(t *testing.T) {
+	_, err := ParseConfirmDelete("sometimes")
+	assert.EqualError(t, err, `invalid confirm_delete "sometimes": use always, never or incomplete`)
+	c, err := ParseConfirmDelete("")
+	require.NoError(t, err)
+	assert.Equal(t, ConfirmAlways, c)
+
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{ConfirmDelete: ConfirmIncomplete})
+	sendKey(m, "delete")
+	assert.Contains(t, m.View(), "Delete 1 task?", "task 1 is open")
+	sendKey(m, "n")
+	assert.Empty(t, fr.deleteTasksCalls)
+	assert.Nil(t, m.prompt)
+
+	sendKey(m, "j")
+	sendKey(m, "delete")
+	assert.Equal(t, [][]int{{2}}, fr.deleteTasksCalls, "complete tasks go without asking")
+
+	tr, fr = newFakeRepo()
+	m = createModel(tr, Options{ConfirmDelete: ConfirmNever})
+	sendKey(m, "delete")
+	assert.Equal(t, [][]int{{1}}, fr.deleteTasksCalls)
+}
+
+func TestModel_ProtectedStatusNamesTheBoundKey(t *testing.T) {
+	keys, err := keymap.Resolve(keymap.Default, map[string][]string{string(keymap.Protect): {"P"}})
+	require.NoError(t, err)
+	tr, fr := newFakeRepo()
+	fr.tasks[0].Protected = true
+	m := createModel(tr, Options{ConfirmDelete: ConfirmNever, Keys: keys})
+
+	sendKey(m, "delete")
+	assert.Empty(t, fr.deleteTasksCalls)
+	assert.Contains(t, m.View(), "Task 1 is protected. Press P to unprotect it first.")
+}
+
+func TestModel_ProtectedTasksAreNotDeleted(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{ConfirmDelete: ConfirmNever})
+
+	sendKey(m, "p")
+	assert.True(t, fr.tasks[0].Protected)
+	assert.Contains(t, m.View(), "1 - A (protected)")
+
+	sendKey(m, "delete")
+	assert.Empty(t, fr.deleteTasksCalls)
+	assert.Contains(t, m.View(), "Task 1 is protected. Press p to unprotect it first.")
+
+	sendKey(m, "p")
+	assert.False(t, fr.tasks[0].Protected, "p unprotects a protected task")
+
+	// Another process protects task 2 after the list loaded it.
+	fr.tasks[1].Protected = true
+	sendKey(m, "j")
+	sendKey(m, "delete")
+	assert.Len(t, fr.tasks, 2, "nothing is deleted")
+	assert.Contains(t, m.View(), "Task 2 is protected.")
+}
+
+func TestModel_Dependencies(t *testing.T) {
+	fr := &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "Design", Version: 1},
+			{Id: 2, Title: "Build", Version: 1},
+			{Id: 3, Title: "Ship", Version: 1},
+		},
+		deps: data.Dependencies{{Blocker: 1, Blocked: 2}, {Blocker: 2, Blocked: 3}},
+	}
+	m := createModel(fr, Options{})
+	m.width, m.height = 120, 40
+	m.applyLayout()
+
+	out := m.View()
+	assert.Contains(t, out, "1 - Design (blocks 2)")
+	assert.Contains(t, out, "2 - Build (blocked by 1) (blocks 3)")
+	assert.Contains(t, out, "3 - Ship (blocked by 2)")
+
+	sendKey(m, "j")
+	assert.Contains(t, m.View(), "Blockers  1")
+
+	// The repository refuses to complete a task with open blockers.
+	fr.failUpdate = &persistence.BlockedError{Id: 2, Blockers: []int{1}}
+	sendKey(m, "x")
+	assert.False(t, m.tasks[2].Complete, "the toggle is undone")
+	assert.Empty(t, m.dirty)
+	assert.Contains(t, m.View(), "Task 2 is blocked by task 1. Complete it first.")
+
+	fr.failUpdate = nil
+	sendKey(m, "k")
+	sendKey(m, "x")
+	m.refresh()
+	out = m.View()
+	assert.Contains(t, out, "2 - Build (blocks 3)", "a complete blocker no longer blocks")
+	assert.NotContains(t, out, "Design (blocks")
+}
+
+func bulkRepo() *fakeRepo {
+	day := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	return &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "A", DueDate: day, Version: 1},
+			{Id: 2, Title: "B", DueDate: day, Version: 1, Tags: []string{"home"}},
+			{Id: 3, Title: "C", DueDate: day, Version: 1},
+			{Id: 4, Title: "D", DueDate: day, Version: 1},
+		},
+	}
+}
+
+func TestModel_Bulk_RangeSelectAndComplete(t *testing.T) {
+	fr := bulkRepo()
+	m := createModel(fr, Options{})
+
+	sendKey(m, "j")
+	sendKey(m, "V")
+	sendKey(m, "j")
+	sendKey(m, "j")
+	assert.Equal(t, []int{2, 3, 4}, m.list.Selected(), "the range follows the cursor")
+	assert.Contains(t, m.View(), "* [ ] 3 - C")
+	sendKey(m, "V")
+	sendKey(m, "k")
+	assert.Equal(t, []int{2, 3, 4}, m.list.Selected(), "ending the range keeps its tasks")
+
+	sendKey(m, "x")
+	require.Len(t, fr.updateTasksCalls, 1, "one transaction")
+	assert.Len(t, fr.updateTasksCalls[0], 3)
+	assert.Empty(t, fr.updateTaskCalls)
+	assert.Equal(t, []int{2, 3, 4}, completeIds(m))
+	assert.Empty(t, m.list.Selected())
+	assert.Contains(t, m.View(), "Updated 3 tasks.")
+
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	sendKey(m, "j")
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	sendKey(m, "x")
+	assert.Equal(t, []int{2}, completeIds(m), "a selection that is all complete is reopened")
+}
+
+// bulkNow is the list's clock in the bulk tests: Friday 3 October 2025.
+func bulkNow() time.Time { return time.Date(2025, time.October, 3, 10, 30, 0, 0, time.UTC) }
+
+func TestModel_Bulk_Reschedule(t *testing.T) {
+	fr := bulkRepo()
+	m := createModel(fr, Options{})
+	m.now = bulkNow
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	sendKey(m, "j")
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+
+	sendKey(m, "r")
+	assert.Contains(t, m.View(), "Reschedule 2 tasks")
+	assert.Contains(t, m.View(), "m - Next month")
+	sendKey(m, "d")
+	assert.Equal(t, "2025-10-04", fr.tasks[0].DueDate.Format(time.DateOnly), "tomorrow")
+	assert.Equal(t, "2025-10-04", fr.tasks[1].DueDate.Format(time.DateOnly))
+	assert.Equal(t, "2025-10-01", fr.tasks[2].DueDate.Format(time.DateOnly))
+
+	sendKey(m, "r")
+	sendKey(m, "w")
+	assert.Equal(t, "2025-10-06", fr.tasks[1].DueDate.Format(time.DateOnly), "with nothing selected, the hovered task moves to Monday")
+	assert.Equal(t, "2025-10-04", fr.tasks[0].DueDate.Format(time.DateOnly))
+
+	sendKey(m, "r")
+	sendKey(m, "m")
+	assert.Equal(t, "2025-11-01", fr.tasks[1].DueDate.Format(time.DateOnly))
+	sendKey(m, "r")
+	sendKey(m, "t")
+	assert.Equal(t, "2025-10-03", fr.tasks[1].DueDate.Format(time.DateOnly))
+
+	sendKey(m, "r")
+	sendKey(m, "p")
+	for _, r := range "2025-12-24" {
+		sendKey(m, string(r))
+	}
+	assert.Contains(t, m.View(), "2025-12-24")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, m.prompt)
+	assert.Equal(t, "2025-12-24", fr.tasks[1].DueDate.Format(time.DateOnly))
+
+	sendKey(m, "r")
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, m.prompt)
+	assert.Len(t, fr.updateTasksCalls, 5, "cancelling writes nothing")
+}
+
+func TestModel_RollOverOverdue(t *testing.T) {
+	fr := bulkRepo()
+	fr.tasks[1].Complete = true
+	fr.tasks[2].DueDate = time.Date(2025, time.October, 9, 0, 0, 0, 0, time.UTC)
+	m := createModel(fr, Options{})
+	m.now = bulkNow
+
+	sendKey(m, "R")
+	if assert.Len(t, fr.updateTasksCalls, 1) {
+		var ids []int
+		for _, task := range fr.updateTasksCalls[0] {
+			ids = append(ids, task.Id)
+		}
+		assert.Equal(t, []int{1, 4}, ids, "complete and future tasks stay put")
+	}
+	for _, task := range fr.tasks {
+		if task.Id == 1 || task.Id == 4 {
+			assert.Equal(t, "2025-10-03", task.DueDate.Format(time.DateOnly))
+		}
+	}
+	assert.Contains(t, m.View(), "Updated 2 tasks.")
+
+	sendKey(m, "R")
+	assert.Len(t, fr.updateTasksCalls, 1)
+	assert.Contains(t, m.View(), "Nothing is overdue.")
+}
+
+func TestModel_Bulk_Tag(t *testing.T) {
+	fr := bulkRepo()
+	m := createModel(fr, Options{})
+	sendKey(m, "V")
+	sendKey(m, "j")
+
+	sendKey(m, "t")
+	for _, r := range "work" {
+		sendKey(m, string(r))
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"work"}, fr.tasks[0].Tags)
+	assert.Equal(t, []string{"home", "work"}, fr.tasks[1].Tags)
+
+	sendKey(m, "t")
+	for _, r := range "-home" {
+		sendKey(m, string(r))
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"work"}, fr.tasks[1].Tags)
+}
+
+func TestModel_Bulk_TagIsValidated(t *testing.T) {
+	fr := bulkRepo()
+	m := createModel(fr, Options{})
+	sendKey(m, "V")
+	sendKey(m, "j")
+
+	sendKey(m, "t")
+	for _, r := range "a,b" {
+		sendKey(m, string(r))
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, fr.updateTasksCalls)
+	assert.NotNil(t, m.prompt, "the prompt stays open to fix the tag")
+	assert.Contains(t, m.View(), `Tag "a,b" must not contain a comma.`)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	sendKey(m, "t")
+	sendKey(m, "-")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, fr.updateTasksCalls, "a bare - changes nothing")
+	assert.Nil(t, m.prompt)
+}
+
+func TestModel_Bulk_ConflictChangesNothing(t *testing.T) {
+	fr := bulkRepo()
+	m := createModel(fr, Options{})
+	sendKey(m, "V")
+	sendKey(m, "j")
+	sendKey(m, "V")
+
+	fr.tasks[1].Title = "B, renamed elsewhere"
+	fr.tasks[1].Version = 2
+	sendKey(m, "x")
+	assert.Empty(t, completeIds(m))
+	assert.Empty(t, fr.updateTasksCalls)
+	assert.Contains(t, m.View(), "Task 2 was changed elsewhere, so nothing was changed.")
+	assert.Contains(t, m.View(), "B, renamed elsewhere", "the list is reloaded")
+	assert.Equal(t, []int{1, 2}, m.list.Selected(), "the selection is kept to retry")
+
+	sendKey(m, "x")
+	assert.Equal(t, []int{1, 2}, completeIds(m))
 }
 
 func TestModel_ErrorMsg_BubblesIntoErr(t *testing.T) {
 	tr, _ := newFakeRepo()
-	m := createModel(tr)
+	m := createModel(tr, Options{})
 
 	e := errors.New("boom")
 	upd, cmd := m.Update(e)
@@ -165,24 +605,426 @@ func TestModel_ErrorMsg_BubblesIntoErr(t *testing.T) {
 
 func TestModel_QuitKeys_Quit(t *testing.T) {
 	tr, _ := newFakeRepo()
-	m := createModel(tr)
+	m := createModel(tr, Options{})
 
-	for _, key := range tui.QuitKeys {
+	for _, key := range keymap.Defaults().Binding(keymap.Quit).Keys() {
 		_, cmd := sendKey(m, key)
 		assert.NotNil(t, cmd)
 
 		assert.Equal(t, tea.Quit(), cmd())
 
-		m = createModel(tr)
+		m = createModel(tr, Options{})
 	}
 }
 
+func TestModel_VimKeys(t *testing.T) {
+	tr, fr := newFakeRepo()
+	vim, err := keymap.Resolve(keymap.Vim, map[string][]string{"toggle": {"c"}})
+	require.NoError(t, err)
+	m := createModel(tr, Options{Keys: vim})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.Equal(t, 2, hoveredId(m))
+
+	sendKey(m, "x")
+	assert.Empty(t, fr.updateTaskCalls, "x is no longer bound")
+	sendKey(m, "c")
+	assert.Len(t, fr.updateTaskCalls, 1)
+
+	sendKey(m, "d")
+	sendKey(m, "y")
+	assert.Equal(t, [][]int{{2}}, fr.deleteTasksCalls)
+
+	assert.Contains(t, m.View(), "c toggle complete")
+	assert.Contains(t, m.View(), "d/delete delete")
+}
+
+func TestModel_HelpOverlay(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{})
+
+	sendKey(m, "?")
+	out := m.View()
+	assert.Contains(t, out, "Keys")
+	assert.Contains(t, out, "ctrl+h")
+	assert.Contains(t, out, "hide completed")
+	assert.Contains(t, out, "space")
+	assert.NotContains(t, out, "1 - A", "the overlay replaces the list")
+
+	_, cmd := sendKey(m, "q")
+	assert.Nil(t, cmd, "the first key only closes the overlay")
+	sendKey(m, "x")
+	assert.Len(t, fr.updateTaskCalls, 1)
+}
+
 func TestModel_NoOpMsg_NoChange(t *testing.T) {
 	tr, _ := newFakeRepo()
-	m := createModel(tr)
+	m := createModel(tr, Options{})
 
 	upd, cmd := m.Update(struct{}{})
 	assert.Same(t, m, upd)
 	assert.Nil(t, cmd)
 
 }
+
+func TestModel_GroupByProject_RendersHeaders(t *testing.T) {
+	fr := &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "A", DueDate: time.Now(), Project: "work"},
+			{Id: 2, Title: "B", Complete: true, DueDate: time.Now(), Project: "home"},
+			{Id: 3, Title: "C", DueDate: time.Now()},
+		},
+	}
+	m := createModel(fr, Options{GroupBy: data.GroupByProject})
+
+	drain(m.Init())
+	out := m.View()
+	assert.Contains(t, out, "home")
+	assert.Contains(t, out, "work")
+	assert.Contains(t, out, data.NoProject)
+	assert.Equal(t, []int{2}, completeIds(m))
+	assert.Equal(t, 2, hoveredId(m), "first group is focused")
+
+	_, cmd := sendKey(m, "x")
+	drain(cmd)
+	assert.Empty(t, completeIds(m))
+	if assert.Len(t, fr.updateTaskCalls, 1) {
+		assert.Equal(t, 2, fr.updateTaskCalls[0].Id)
+		assert.False(t, fr.updateTaskCalls[0].Complete)
+	}
+}
+
+func TestTaskLabel_RendersRelativeDue(t *testing.T) {
+	now := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+
+	overdue := taskLabel(data.Task{Title: "Late", DueDate: now.AddDate(0, 0, -2)}, nil, theme.Default(), now)
+	assert.Contains(t, overdue, "Late ~ ")
+	assert.Contains(t, overdue, "overdue 2d (2025-09-29)")
+
+	soon := taskLabel(data.Task{Title: "Soon", DueDate: now.AddDate(0, 0, 3)}, nil, theme.Default(), now)
+	assert.Contains(t, soon, "in 3d (2025-10-04)")
+
+	assert.Equal(t, "Someday", taskLabel(data.Task{Title: "Someday"}, nil, theme.Default(), now))
+}
+
+func TestModel_DetailPane_ShowsHoveredTask(t *testing.T) {
+	fr := &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "A task with a title long enough to be truncated by the list", DueDate: time.Now(), Project: "work", Tags: []string{"docs", "q4"}, Notes: "check the appendix", CreatedAt: time.Date(2025, time.September, 30, 8, 15, 0, 0, time.Local)},
+			{Id: 2, Title: "B", DueDate: time.Now()},
+		},
+	}
+	m := createModel(fr, Options{})
+	drain(m.Init())
+
+	for _, width := range []int{140, 60} {
+		upd, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 40})
+		out := upd.(*model).View()
+		assert.Contains(t, out, "work", "width %d", width)
+		assert.Contains(t, out, "docs, q4", "width %d", width)
+		assert.Contains(t, out, "check the appendix", "width %d", width)
+		assert.Contains(t, out, "2025-09-30 08:15", "width %d", width)
+	}
+}
+
+func TestLayout_SwitchesToSideBySideWhenWide(t *testing.T) {
+	assert.Equal(t, layout{}, newLayout(0, 0))
+
+	narrow := newLayout(sideBySideMinWidth-1, 40)
+	assert.False(t, narrow.sideBySide)
+	assert.Equal(t, sideBySideMinWidth-1, narrow.detailWidth)
+	assert.Equal(t, 20, narrow.listHeight)
+
+	wide := newLayout(150, 40)
+	assert.True(t, wide.sideBySide)
+	assert.Equal(t, 150, wide.listWidth+wide.detailWidth)
+}
+
+func TestModel_HistoryTab_LoadsAndCachesHoveredTaskHistory(t *testing.T) {
+	tr, fr := newFakeRepo()
+	fr.history = map[int][]data.Event{
+		1: {
+			{Id: 1, TaskId: 1, Kind: data.EventCreated, At: time.Date(2025, time.September, 30, 8, 0, 0, 0, time.Local), Changes: []data.Change{{Field: "title", New: "A0"}}},
+			{Id: 2, TaskId: 1, Kind: data.EventUpdated, At: time.Date(2025, time.October, 1, 8, 0, 0, 0, time.Local), Changes: []data.Change{{Field: "title", Old: "A0", New: "A"}}},
+		},
+	}
+	m := createModel(tr, Options{})
+	drain(m.Init())
+
+	_, _ = m.Update(struct{}{})
+	assert.Empty(t, fr.historyCalls, "history is only fetched when its tab is open")
+
+	upd, _ := sendKey(m, "ctrl+t")
+	got := upd.(*model)
+	assert.Equal(t, historyTab, got.detailTab)
+	assert.Equal(t, []int{1}, fr.historyCalls)
+
+	out := got.View()
+	assert.Contains(t, out, "2025-10-01 08:00 updated")
+	assert.Contains(t, out, `title: "A0" → "A"`)
+
+	_, _ = m.Update(struct{}{})
+	assert.Equal(t, []int{1}, fr.historyCalls, "history is cached")
+
+	upd, _ = sendKey(m, "ctrl+t")
+	assert.Equal(t, detailsTab, upd.(*model).detailTab)
+}
+
+func TestModel_Refresh_ReloadsExternalChangesKeepingCursor(t *testing.T) {
+	fr := &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "A", DueDate: time.Now()},
+			{Id: 2, Title: "B", DueDate: time.Now()},
+			{Id: 3, Title: "C", Complete: true, DueDate: time.Now()},
+		},
+	}
+	m := createModel(fr, Options{})
+	drain(m.Init())
+	sendKey(m, "j")
+	require.Equal(t, 2, hoveredId(m))
+
+	m.Update(pollMsg{})
+	assert.Equal(t, 1, fr.findCalls, "an unchanged database is not reloaded")
+
+	fr.tasks = append([]data.Task{{Id: 4, Title: "Added elsewhere", DueDate: time.Now()}}, fr.tasks...)
+	fr.version++
+	_, cmd := m.Update(pollMsg{})
+	assert.NotNil(t, cmd)
+	assert.Len(t, m.tasks, 4)
+	assert.Contains(t, m.View(), "Added elsewhere")
+	assert.Equal(t, 2, hoveredId(m), "the cursor follows its task")
+	assert.Equal(t, []int{3}, completeIds(m))
+
+	fr.tasks = append(fr.tasks[:2], fr.tasks[3])
+	fr.version++
+	m.Update(pollMsg{})
+	assert.Equal(t, 3, hoveredId(m), "the cursor keeps its place when its task is deleted")
+}
+
+func TestModel_Refresh_RestoresCursorAcrossGroups(t *testing.T) {
+	fr := &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "A", DueDate: time.Now(), Project: "home"},
+			{Id: 2, Title: "B", DueDate: time.Now(), Project: "work"},
+			{Id: 3, Title: "C", DueDate: time.Now(), Project: "work"},
+		},
+	}
+	m := createModel(fr, Options{GroupBy: data.GroupByProject})
+	drain(m.Init())
+	sendKey(m, "j")
+	sendKey(m, "j")
+	require.Equal(t, 3, hoveredId(m))
+
+	fr.tasks[0].Title = "A, renamed"
+	fr.version++
+	m.Update(pollMsg{})
+	assert.Equal(t, 3, hoveredId(m))
+	assert.Contains(t, m.View(), "A, renamed")
+}
+
+func TestModel_PromptsUseTheKeyMap(t *testing.T) {
+	keys, err := keymap.Resolve(keymap.Default, map[string][]string{
+		"confirm": {"Y"}, "snooze-tomorrow": {"T"}, "keep-theirs": {"k"},
+	})
+	require.NoError(t, err)
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{Keys: keys})
+
+	sendKey(m, "?")
+	out := m.View()
+	assert.Contains(t, out, "Reschedule")
+	assert.Regexp(t, `T\s+tomorrow`, out)
+	assert.Regexp(t, `o\s+overwrite with yours`, out)
+	sendKey(m, "x")
+
+	sendKey(m, "delete")
+	assert.Contains(t, m.View(), "Y - Delete")
+	sendKey(m, "y")
+	assert.Empty(t, fr.deleteTasksCalls, "y is not bound to confirm")
+	sendKey(m, "Y")
+	assert.Equal(t, [][]int{{1}}, fr.deleteTasksCalls)
+
+	sendKey(m, "r")
+	assert.Contains(t, m.View(), "T - Tomorrow")
+	sendKey(m, "T")
+	assert.Nil(t, m.prompt)
+	if assert.Len(t, fr.updateTasksCalls, 1) {
+		assert.Equal(t, m.now().AddDate(0, 0, 1).Format(time.DateOnly), fr.updateTasksCalls[0][0].DueDate.Format(time.DateOnly))
+	}
+
+	fr.tasks[0].Title = "B, renamed elsewhere"
+	fr.tasks[0].Version = 2
+	sendKey(m, "x")
+	require.NotNil(t, m.conflict)
+	assert.Contains(t, m.View(), "k - Keep theirs")
+	sendKey(m, "k")
+	assert.Nil(t, m.conflict)
+}
+
+func TestModel_Conflict_PromptsAndResolves(t *testing.T) {
+	fr := &fakeRepo{
+		tasks: []data.Task{
+			{Id: 1, Title: "A", DueDate: time.Now(), Version: 1},
+			{Id: 2, Title: "B", DueDate: time.Now(), Version: 1},
+		},
+	}
+	m := createModel(fr, Options{})
+	drain(m.Init())
+
+	// Another process renames task 1 before the list notices.
+	fr.tasks[0].Title = "A, renamed elsewhere"
+	fr.tasks[0].Version = 2
+
+	sendKey(m, "x")
+	require.NotNil(t, m.conflict)
+	out := m.View()
+	assert.Contains(t, out, "Task 1 was changed elsewhere")
+	assert.Contains(t, out, `title: "A, renamed elsewhere" → "A"`)
+	assert.Contains(t, out, `complete: "false" → "true"`)
+	assert.Equal(t, "A, renamed elsewhere", fr.tasks[0].Title, "nothing is written until the user decides")
+
+	sendKey(m, "j")
+	assert.NotNil(t, m.conflict, "other keys leave the prompt open")
+
+	sendKey(m, "t")
+	assert.Nil(t, m.conflict)
+	assert.Equal(t, "A, renamed elsewhere", m.tasks[1].Title, "keeping theirs reloads the list")
+	assert.Empty(t, completeIds(m))
+
+	fr.tasks[0].Notes = "edited again"
+	fr.tasks[0].Version = 3
+	sendKey(m, "x")
+	require.NotNil(t, m.conflict)
+	sendKey(m, "o")
+	assert.Nil(t, m.conflict)
+	assert.True(t, fr.tasks[0].Complete)
+	assert.Empty(t, fr.tasks[0].Notes, "overwriting stores the list's copy")
+	assert.Equal(t, 4, fr.tasks[0].Version)
+	assert.Equal(t, []int{1}, completeIds(m))
+
+	sendKey(m, "x")
+	assert.Nil(t, m.conflict, "the list tracks versions across its own writes")
+	assert.False(t, fr.tasks[0].Complete)
+}
+
+func TestModel_Quit_SavesOnlyDirtyTasks(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{})
+	drain(m.Init())
+
+	sendKey(m, "x")
+	assert.Len(t, fr.updateTaskCalls, 1, "toggles are saved straight away")
+	assert.Empty(t, m.dirty)
+
+	sendKey(m, "q")
+	assert.Empty(t, fr.updateTasksCalls, "nothing is left to save on quit")
+}
+
+func TestModel_Quit_RetriesFailedSaves(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{})
+	drain(m.Init())
+
+	fr.failUpdate = errors.New("database is locked")
+	sendKey(m, "x")
+	assert.Equal(t, map[int]bool{1: true}, m.dirty)
+	assert.False(t, fr.tasks[0].Complete)
+
+	fr.failUpdate = nil
+	sendKey(m, "q")
+	if assert.Len(t, fr.updateTasksCalls, 1) && assert.Len(t, fr.updateTasksCalls[0], 1) {
+		assert.Equal(t, 1, fr.updateTasksCalls[0][0].Id)
+	}
+	assert.True(t, fr.tasks[0].Complete)
+	assert.Empty(t, m.dirty)
+}
+
+func TestModel_AddTask_SavesBeforeLeaving(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{})
+	drain(m.Init())
+
+	fr.failUpdate = errors.New("database is locked")
+	sendKey(m, "x")
+	fr.failUpdate = nil
+	sendKey(m, "ctrl+a")
+	assert.True(t, fr.tasks[0].Complete)
+	assert.True(t, fr.closed)
+	assert.NoError(t, m.Err())
+	assert.Equal(t, tui.AddTask, m.Next())
+}
+
+func TestModel_Quit_ReportsSavesThatStillFail(t *testing.T) {
+	tr, fr := newFakeRepo()
+	m := createModel(tr, Options{})
+	drain(m.Init())
+
+	fr.failUpdate = errors.New("database is locked")
+	sendKey(m, "x")
+	sendKey(m, "q")
+	assert.EqualError(t, m.Err(), "database is locked")
+	assert.False(t, fr.tasks[0].Complete)
+	assert.True(t, fr.closed)
+}
+
+func manyTasks(n int) *fakeRepo {
+	fr := &fakeRepo{tasks: make([]data.Task, n)}
+	for i := range fr.tasks {
+		fr.tasks[i] = data.Task{Id: i + 1, Title: "Task " + strconv.Itoa(i+1)}
+	}
+	return fr
+}
+
+func TestModel_LargeList_ReadsAndRendersOnlyWhatIsInView(t *testing.T) {
+	fr := manyTasks(5000)
+	m := createModel(fr, Options{})
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	assert.Equal(t, 1, fr.findCalls)
+	assert.Len(t, m.tasks, pageSize)
+	assert.Less(t, strings.Count(m.View(), "\n"), 60, "only the rows in view are rendered")
+
+	for range pageSize {
+		sendKey(m, "j")
+	}
+	assert.Equal(t, pageSize+1, hoveredId(m))
+	assert.Len(t, m.tasks, 2*pageSize, "the next page is read as the cursor nears it")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	assert.Equal(t, 5000, hoveredId(m))
+	assert.Contains(t, m.View(), "5000 - Task 5000")
+}
+
+// BenchmarkModel_50k measures opening a 50k task list and moving through it.
+func BenchmarkModel_50k(b *testing.B) {
+	fr := manyTasks(50_000)
+	b.Run("open", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m := createModel(fr, Options{})
+			_ = m.View()
+		}
+	})
+	b.Run("keypress", func(b *testing.B) {
+		m := createModel(fr, Options{})
+		m.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+		for i := 0; i < b.N; i++ {
+			sendKey(m, "j")
+			_ = m.View()
+		}
+	})
+	b.Run("reload after deleting hovered", func(b *testing.B) {
+		all := slices.Clone(fr.tasks)
+		m := createModel(fr, Options{})
+		m.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+		sendKey(m, "j")
+		hovered := hoveredId(m)
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			fr.tasks = slices.Clone(all)
+			m.refresh()
+			fr.tasks = slices.DeleteFunc(fr.tasks, func(t data.Task) bool { return t.Id == hovered })
+			b.StartTimer()
+			m.refresh()
+		}
+	})
+}
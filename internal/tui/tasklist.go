@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TaskRow is one line of a TaskList: a group heading, or a task. Rows of a
+// task that sits in several groups share one copy of it, so a change made
+// through one shows in all.
+type TaskRow struct {
+	Heading string
+	Task    *data.Task
+}
+
+// TaskAction is something done to the task under the cursor when a key
+// bound to Action is pressed.
+type TaskAction struct {
+	Action keymap.Action
+	Do     func(task *data.Task) error
+	// Bulk, when set, runs instead of Do while tasks are selected, on the
+	// selected tasks.
+	Bulk func(tasks []*data.Task) error
+}
+
+// TaskList is a scrolling list of tasks. Its cursor stays on the same task
+// when the list is reloaded, and tasks can be selected by id independently
+// of whether they are complete: one at a time, or as the range of rows
+// between where the visual action was pressed and the cursor.
+type TaskList struct {
+	// Label draws a task after its checkbox and id.
+	Label func(task data.Task) string
+	// Actions run on the task under the cursor when one of their keys is
+	// pressed.
+	Actions []TaskAction
+
+	keys     keymap.KeyMap
+	theme    theme.Theme
+	list     *VirtualList[TaskRow]
+	selected map[int]bool
+	// anchor is the row a range selection started at, or -1.
+	anchor int
+	// ranged holds the ids in the range while the view is drawn.
+	ranged map[int]bool
+}
+
+func NewTaskList(t theme.Theme, keys keymap.KeyMap) *TaskList {
+	l := &TaskList{
+		Label:    func(task data.Task) string { return task.Title },
+		keys:     keys,
+		theme:    t,
+		selected: map[int]bool{},
+		anchor:   -1,
+	}
+	l.list = NewVirtualList(l.render)
+	l.list.Skip = func(r TaskRow) bool { return r.Task == nil }
+	l.list.Keys = keys
+	return l
+}
+
+// Reset replaces the rows with those from load, clearing the selection and
+// putting the cursor on the first task.
+func (l *TaskList) Reset(load PageLoader[TaskRow]) error {
+	l.ClearSelection()
+	return l.list.Reset(load)
+}
+
+// Reload replaces the rows with those from load, keeping the selection and
+// the cursor on the task it was on. When that task has gone, the cursor
+// stays at the same position instead.
+func (l *TaskList) Reload(load PageLoader[TaskRow]) error {
+	// Rows move on reload, so a range is kept as the tasks it covers.
+	l.endRange()
+	hovered, ok := l.Current()
+	position := l.list.Index()
+	// The task is looked for only as far as rows were loaded, and a screen
+	// more for tasks added above it, so one that has gone does not read in
+	// the rest of the list.
+	limit := l.list.Len() + l.list.height
+	if err := l.list.Reset(load); err != nil {
+		return err
+	}
+	if ok {
+		found, err := l.list.Focus(func(r TaskRow) bool { return r.Task.Id == hovered.Id }, limit)
+		if err != nil || found {
+			return err
+		}
+	}
+	return l.list.MoveTo(position)
+}
+
+// Focus puts the cursor on the task with id, loading pages until it is
+// found. It reports false, leaving the cursor alone, when it is not listed.
+func (l *TaskList) Focus(id int) (bool, error) {
+	return l.list.Focus(func(r TaskRow) bool { return r.Task.Id == id }, math.MaxInt)
+}
+
+// SetHeight sets how many rows are shown.
+func (l *TaskList) SetHeight(height int) { l.list.SetHeight(height) }
+
+// Index is the cursor's row.
+func (l *TaskList) Index() int { return l.list.Index() }
+
+// Current returns the task under the cursor, if there is one.
+func (l *TaskList) Current() (*data.Task, bool) {
+	r, ok := l.list.Current()
+	return r.Task, ok
+}
+
+// Selected returns the ids of the selected tasks in ascending order.
+func (l *TaskList) Selected() []int {
+	ids := make([]int, 0, len(l.selected))
+	for id := range l.selected {
+		ids = append(ids, id)
+	}
+	for id := range l.rangeIds() {
+		if !l.selected[id] {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// SelectedTasks returns the selected tasks that are loaded, in list order.
+func (l *TaskList) SelectedTasks() []*data.Task {
+	ranged := l.rangeIds()
+	seen := map[int]bool{}
+	var tasks []*data.Task
+	for _, r := range l.list.items {
+		if r.Task == nil || seen[r.Task.Id] || !l.selected[r.Task.Id] && !ranged[r.Task.Id] {
+			continue
+		}
+		seen[r.Task.Id] = true
+		tasks = append(tasks, r.Task)
+	}
+	return tasks
+}
+
+// Targets returns the tasks an action applies to: the selected ones, or the
+// one under the cursor when none are selected.
+func (l *TaskList) Targets() []*data.Task {
+	if tasks := l.SelectedTasks(); len(tasks) > 0 {
+		return tasks
+	}
+	if task, ok := l.Current(); ok {
+		return []*data.Task{task}
+	}
+	return nil
+}
+
+func (l *TaskList) IsSelected(id int) bool { return l.selected[id] || l.rangeIds()[id] }
+
+func (l *TaskList) SetSelected(id int, selected bool) {
+	if selected {
+		l.selected[id] = true
+	} else {
+		delete(l.selected, id)
+	}
+}
+
+func (l *TaskList) ClearSelection() {
+	clear(l.selected)
+	l.anchor = -1
+}
+
+// rangeIds returns the ids of the tasks between the anchor and the cursor.
+func (l *TaskList) rangeIds() map[int]bool {
+	if l.anchor < 0 {
+		return nil
+	}
+	ids := map[int]bool{}
+	from, to := min(l.anchor, l.list.Index()), max(l.anchor, l.list.Index())
+	for _, r := range l.list.items[from : min(to, len(l.list.items)-1)+1] {
+		if r.Task != nil {
+			ids[r.Task.Id] = true
+		}
+	}
+	return ids
+}
+
+// endRange adds the tasks in the range to the selection.
+func (l *TaskList) endRange() {
+	for id := range l.rangeIds() {
+		l.selected[id] = true
+	}
+	l.anchor = -1
+}
+
+// Update handles a key: the select action marks the task under the cursor,
+// the visual action starts or ends a range, an action's key runs it and
+// anything else moves the cursor. Errors are returned as messages.
+func (l *TaskList) Update(msg tea.Msg) tea.Cmd {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	task, hovered := l.Current()
+	if l.keys.Matches(k, keymap.Select) {
+		if hovered {
+			l.SetSelected(task.Id, !l.selected[task.Id])
+		}
+		return nil
+	}
+	if l.keys.Matches(k, keymap.Visual) {
+		if l.anchor >= 0 {
+			l.endRange()
+		} else if hovered {
+			l.anchor = l.list.Index()
+		}
+		return nil
+	}
+	for _, action := range l.Actions {
+		if !l.keys.Matches(k, action.Action) {
+			continue
+		}
+		var err error
+		if selected := l.SelectedTasks(); action.Bulk != nil && len(selected) > 0 {
+			err = action.Bulk(selected)
+		} else if hovered {
+			err = action.Do(task)
+		}
+		if err != nil {
+			return func() tea.Msg { return err }
+		}
+		return nil
+	}
+	return l.list.Update(msg)
+}
+
+func (l *TaskList) View() string {
+	l.ranged = l.rangeIds()
+	return l.list.View()
+}
+
+func (l *TaskList) render(r TaskRow, hovered bool) string {
+	header := l.theme.Style(theme.Header)
+	if r.Task == nil {
+		return header.Render(r.Heading)
+	}
+	cursor, mark, check := "  ", "  ", "[ ]"
+	if hovered {
+		cursor = header.Render("> ")
+	}
+	if l.selected[r.Task.Id] || l.ranged[r.Task.Id] {
+		mark = header.Render("* ")
+	}
+	if r.Task.Complete {
+		check = "[x]"
+	}
+	return fmt.Sprintf("%s%s%s %d - %s", cursor, mark, check, r.Task.Id, l.Label(*r.Task))
+}
@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	"github.com/ake3mio/go-todo-cli/internal/tui/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taskRows lists tasks under a heading, reading them all as one page.
+func taskRows(heading string, tasks ...data.Task) PageLoader[TaskRow] {
+	return func() ([]TaskRow, bool, error) {
+		rows := []TaskRow{{Heading: heading}}
+		for i := range tasks {
+			rows = append(rows, TaskRow{Task: &tasks[i]})
+		}
+		return rows, false, nil
+	}
+}
+
+func keys(l *TaskList, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace}
+		case "delete":
+			msg = tea.KeyMsg{Type: tea.KeyDelete}
+		}
+		cmd = l.Update(msg)
+	}
+	return cmd
+}
+
+func hovered(l *TaskList) int {
+	task, _ := l.Current()
+	if task == nil {
+		return 0
+	}
+	return task.Id
+}
+
+func TestTaskList_SelectionIsDistinctFromCompletion(t *testing.T) {
+	l := NewTaskList(theme.Default(), keymap.Defaults())
+	require.NoError(t, l.Reset(taskRows("Tasks", data.Task{Id: 1, Title: "A"}, data.Task{Id: 2, Title: "B", Complete: true}, data.Task{Id: 3, Title: "C"})))
+
+	assert.Equal(t, 1, hovered(l), "the cursor skips the heading")
+	keys(l, " ", "j", "j", " ")
+	assert.Equal(t, []int{1, 3}, l.Selected())
+
+	out := l.View()
+	assert.Contains(t, out, "* [ ] 1 - A")
+	assert.Contains(t, out, "  [x] 2 - B")
+	assert.Contains(t, out, "* [ ] 3 - C")
+
+	keys(l, "k", " ")
+	assert.Equal(t, []int{1, 2, 3}, l.Selected(), "complete tasks can be selected too")
+	keys(l, " ")
+	assert.Equal(t, []int{1, 3}, l.Selected())
+
+	l.ClearSelection()
+	assert.Empty(t, l.Selected())
+}
+
+func TestTaskList_ReloadKeepsCursorAndSelection(t *testing.T) {
+	l := NewTaskList(theme.Default(), keymap.Defaults())
+	require.NoError(t, l.Reset(taskRows("Tasks", data.Task{Id: 1}, data.Task{Id: 2}, data.Task{Id: 3})))
+	keys(l, "j", " ")
+	require.Equal(t, 2, hovered(l))
+
+	require.NoError(t, l.Reload(taskRows("Tasks", data.Task{Id: 4}, data.Task{Id: 3}, data.Task{Id: 1}, data.Task{Id: 2})))
+	assert.Equal(t, 2, hovered(l), "the cursor follows its task")
+	assert.Equal(t, []int{2}, l.Selected())
+
+	require.NoError(t, l.Reload(taskRows("Tasks", data.Task{Id: 4}, data.Task{Id: 3}, data.Task{Id: 1})))
+	assert.Equal(t, 1, hovered(l), "the cursor keeps its place when its task has gone")
+
+	require.NoError(t, l.Reset(taskRows("Tasks", data.Task{Id: 1})))
+	assert.Empty(t, l.Selected(), "a reset clears the selection")
+}
+
+func TestTaskList_ReloadDoesNotReadEveryPageForAGoneTask(t *testing.T) {
+	pages := 0
+	rows := func(skip int) PageLoader[TaskRow] {
+		next := 1
+		return func() ([]TaskRow, bool, error) {
+			pages++
+			var page []TaskRow
+			for ; next <= 1000 && len(page) < 10; next++ {
+				if next != skip {
+					page = append(page, TaskRow{Task: &data.Task{Id: next}})
+				}
+			}
+			return page, next <= 1000, nil
+		}
+	}
+	l := NewTaskList(theme.Default(), keymap.Defaults())
+	require.NoError(t, l.Reset(rows(0)))
+	keys(l, "j", "j")
+	require.Equal(t, 3, hovered(l))
+
+	pages = 0
+	require.NoError(t, l.Reload(rows(3)))
+	assert.Equal(t, 4, hovered(l), "the cursor keeps its place")
+	assert.Less(t, pages, 10)
+}
+
+func TestTaskList_ActionsRunOnHoveredTask(t *testing.T) {
+	tasks := []data.Task{{Id: 1, Project: "work"}, {Id: 2, Project: "home"}}
+	// Task 1 is listed in both groups; its rows share one copy.
+	load := func() ([]TaskRow, bool, error) {
+		return []TaskRow{
+			{Heading: "work"}, {Task: &tasks[0]},
+			{Heading: "all"}, {Task: &tasks[0]}, {Task: &tasks[1]},
+		}, false, nil
+	}
+	boom := errors.New("boom")
+	var deleted []int
+	l := NewTaskList(theme.Default(), keymap.Defaults())
+	l.Actions = []TaskAction{
+		{Action: keymap.Toggle, Do: func(task *data.Task) error {
+			task.Complete = !task.Complete
+			return nil
+		}},
+		{Action: keymap.Delete, Do: func(task *data.Task) error {
+			if task.Id == 2 {
+				return boom
+			}
+			deleted = append(deleted, task.Id)
+			return nil
+		}},
+	}
+	require.NoError(t, l.Reset(load))
+
+	assert.Nil(t, keys(l, "x"))
+	assert.True(t, tasks[0].Complete)
+	assert.Equal(t, 1, l.Index(), "actions leave the cursor alone")
+	assert.Contains(t, l.View(), ">   [x] 1")
+	keys(l, "j")
+	assert.Contains(t, l.View(), "\n    [x] 1", "every row of the task shows the change")
+
+	keys(l, "delete")
+	keys(l, "j")
+	cmd := keys(l, "delete")
+	require.NotNil(t, cmd)
+	assert.Equal(t, boom, cmd())
+	assert.Equal(t, []int{1}, deleted)
+}
+
+func TestTaskList_RangeSelectionAndBulkActions(t *testing.T) {
+	var single, bulk []int
+	l := NewTaskList(theme.Default(), keymap.Defaults())
+	l.Actions = []TaskAction{{
+		Action: keymap.Toggle,
+		Do:     func(task *data.Task) error { single = append(single, task.Id); return nil },
+		Bulk: func(tasks []*data.Task) error {
+			for _, task := range tasks {
+				bulk = append(bulk, task.Id)
+			}
+			return nil
+		},
+	}}
+	require.NoError(t, l.Reset(taskRows("Tasks", data.Task{Id: 1}, data.Task{Id: 2}, data.Task{Id: 3}, data.Task{Id: 4})))
+
+	keys(l, "x")
+	assert.Equal(t, []int{1}, single, "without a selection the hovered task is acted on")
+
+	keys(l, "j", "j", "V", "k")
+	assert.Equal(t, []int{2, 3}, l.Selected())
+	assert.Contains(t, l.View(), "> * [ ] 2")
+	keys(l, "k", "k")
+	assert.Equal(t, []int{1, 2, 3}, l.Selected(), "the range can cover the rows above the anchor")
+
+	keys(l, "V", "G", " ")
+	assert.Equal(t, []int{1, 2, 3, 4}, l.Selected())
+	assert.Equal(t, []int{1, 2, 3, 4}, idsOf(l.Targets()))
+
+	require.NoError(t, l.Reload(taskRows("Tasks", data.Task{Id: 4}, data.Task{Id: 3}, data.Task{Id: 2}, data.Task{Id: 1})))
+	keys(l, "x")
+	assert.Equal(t, []int{4, 3, 2, 1}, bulk, "bulk actions get the selected tasks in list order")
+	assert.Equal(t, []int{1}, single)
+}
+
+func idsOf(tasks []*data.Task) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.Id
+	}
+	return ids
+}
+
+func TestTaskList_Empty(t *testing.T) {
+	ran := false
+	l := NewTaskList(theme.Default(), keymap.Defaults())
+	l.Actions = []TaskAction{{Action: keymap.Toggle, Do: func(*data.Task) error { ran = true; return nil }}}
+	require.NoError(t, l.Reset(taskRows("Tasks")))
+
+	assert.Nil(t, keys(l, "x", " ", "j", "G"))
+	assert.False(t, ran)
+	assert.Empty(t, l.Selected())
+	_, ok := l.Current()
+	assert.False(t, ok)
+}
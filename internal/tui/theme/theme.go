@@ -0,0 +1,176 @@
+package theme
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type Role string
+
+const (
+	Overdue  Role = "overdue"
+	Today    Role = "today"
+	Soon     Role = "soon"
+	Later    Role = "later"
+	Done     Role = "done"
+	Header   Role = "header"
+	Help     Role = "help"
+	Empty    Role = "empty"
+	Error    Role = "error"
+	baseRole      = "base"
+)
+
+var Roles = []Role{Overdue, Today, Soon, Later, Done, Header, Help, Empty, Error}
+
+const (
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+)
+
+// Theme maps each Role to a lipgloss colour. An empty colour leaves the
+// terminal's default foreground in place.
+type Theme struct {
+	Name   string
+	Colors map[Role]string
+	Bold   bool
+}
+
+var builtins = map[string]Theme{
+	Dark: {
+		Name: Dark,
+		Colors: map[Role]string{
+			Overdue: "1",
+			Today:   "11",
+			Soon:    "6",
+			Later:   "",
+			Done:    "8",
+			Header:  "5",
+			Help:    "3",
+			Empty:   "2",
+			Error:   "1",
+		},
+	},
+	Light: {
+		Name: Light,
+		Colors: map[Role]string{
+			Overdue: "#B00020",
+			Today:   "#B35C00",
+			Soon:    "#005F87",
+			Later:   "",
+			Done:    "#8A8A8A",
+			Header:  "#6A1B9A",
+			Help:    "#7A6000",
+			Empty:   "#1B5E20",
+			Error:   "#B00020",
+		},
+	},
+	HighContrast: {
+		Name: HighContrast,
+		Bold: true,
+		Colors: map[Role]string{
+			Overdue: "9",
+			Today:   "11",
+			Soon:    "14",
+			Later:   "15",
+			Done:    "7",
+			Header:  "13",
+			Help:    "15",
+			Empty:   "10",
+			Error:   "9",
+		},
+	},
+}
+
+// Default is the theme used when no theme is configured.
+func Default() Theme {
+	return builtins[Dark]
+}
+
+// Builtins lists the names of the built-in themes.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve looks name up among the user themes first and the built-ins second.
+// A user theme starts from the built-in named by its "base" key (dark when
+// omitted) and overrides individual roles.
+func Resolve(name string, custom map[string]map[string]string) (Theme, error) {
+	if name == "" {
+		return Default(), nil
+	}
+	if overrides, ok := custom[name]; ok {
+		baseName := overrides[baseRole]
+		if baseName == "" {
+			baseName = Dark
+		}
+		base, ok := builtins[baseName]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %q: unknown base theme %q", name, baseName)
+		}
+		t := Theme{Name: name, Bold: base.Bold, Colors: make(map[Role]string, len(base.Colors))}
+		for role, color := range base.Colors {
+			t.Colors[role] = color
+		}
+		for role, color := range overrides {
+			if role == baseRole {
+				continue
+			}
+			if !isRole(Role(role)) {
+				return Theme{}, fmt.Errorf("theme %q: unknown colour role %q", name, role)
+			}
+			t.Colors[Role(role)] = color
+		}
+		return t, nil
+	}
+	if t, ok := builtins[name]; ok {
+		return t, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q", name)
+}
+
+func isRole(r Role) bool {
+	for _, role := range Roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Style returns a foreground style for role. A zero Theme falls back to the
+// default theme so components can hold one without initialising it.
+func (t Theme) Style(role Role) lipgloss.Style {
+	if t.Colors == nil {
+		t = Default()
+	}
+	style := lipgloss.NewStyle().Bold(t.Bold)
+	if color := t.Colors[role]; color != "" {
+		style = style.Foreground(lipgloss.Color(color))
+	}
+	return style
+}
+
+// UrgencyRole maps a task's urgency to the role its due date is drawn with.
+func UrgencyRole(urgency data.Urgency) Role {
+	switch urgency {
+	case data.UrgencyOverdue:
+		return Overdue
+	case data.UrgencyToday:
+		return Today
+	case data.UrgencySoon:
+		return Soon
+	case data.UrgencyLater:
+		return Later
+	default:
+		return Done
+	}
+}
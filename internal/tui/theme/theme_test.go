@@ -0,0 +1,57 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve_EmptyName_ReturnsDefault(t *testing.T) {
+	got, err := Resolve("", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Dark, got.Name)
+}
+
+func TestResolve_Builtins(t *testing.T) {
+	for _, name := range Builtins() {
+		got, err := Resolve(name, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, name, got.Name)
+		for _, role := range Roles {
+			_, ok := got.Colors[role]
+			assert.True(t, ok, "theme %s should define role %s", name, role)
+		}
+	}
+}
+
+func TestResolve_UserTheme_OverridesBase(t *testing.T) {
+	custom := map[string]map[string]string{
+		"mine": {"base": Light, "overdue": "#FF0000"},
+	}
+	got, err := Resolve("mine", custom)
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", got.Name)
+	assert.Equal(t, "#FF0000", got.Colors[Overdue])
+	assert.Equal(t, builtins[Light].Colors[Soon], got.Colors[Soon])
+	assert.NotEqual(t, "#FF0000", builtins[Light].Colors[Overdue], "built-ins must not be mutated")
+}
+
+func TestResolve_Errors(t *testing.T) {
+	_, err := Resolve("missing", nil)
+	assert.Error(t, err)
+
+	_, err = Resolve("mine", map[string]map[string]string{"mine": {"base": "sepia"}})
+	assert.ErrorContains(t, err, "sepia")
+
+	_, err = Resolve("mine", map[string]map[string]string{"mine": {"background": "0"}})
+	assert.ErrorContains(t, err, "background")
+}
+
+func TestUrgencyRole(t *testing.T) {
+	assert.Equal(t, Overdue, UrgencyRole(data.UrgencyOverdue))
+	assert.Equal(t, Today, UrgencyRole(data.UrgencyToday))
+	assert.Equal(t, Soon, UrgencyRole(data.UrgencySoon))
+	assert.Equal(t, Later, UrgencyRole(data.UrgencyLater))
+	assert.Equal(t, Done, UrgencyRole(data.UrgencyNone))
+}
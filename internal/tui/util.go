@@ -1,13 +1,27 @@
 package tui
 
-import tea "github.com/charmbracelet/bubbletea"
-
-func Quit(key string, cleanup func()) tea.Cmd {
-	for _, k := range QuitKeys {
-		if key == k {
-			cleanup()
-			return tea.Quit
-		}
+import (
+	"fmt"
+
+	"github.com/ake3mio/go-todo-cli/internal/data"
+	"github.com/ake3mio/go-todo-cli/internal/persistence"
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func Quit(keys keymap.KeyMap, msg tea.KeyMsg, cleanup func()) tea.Cmd {
+	if keys.Matches(msg, keymap.Quit) {
+		cleanup()
+		return tea.Quit
 	}
 	return nil
 }
+
+// BlockedStatus tells the user why a task could not be completed and what
+// to do about it.
+func BlockedStatus(b *persistence.BlockedError) string {
+	if len(b.Blockers) == 1 {
+		return fmt.Sprintf("Task %d is blocked by task %d. Complete it first.", b.Id, b.Blockers[0])
+	}
+	return fmt.Sprintf("Task %d is blocked by tasks %s. Complete them first.", b.Id, data.JoinIds(b.Blockers))
+}
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ake3mio/go-todo-cli/internal/tui/keymap"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultListHeight is how many rows a VirtualList shows until it is given
+// a height.
+const defaultListHeight = 10
+
+// PageLoader returns the next page of a list's items and whether more pages
+// follow.
+type PageLoader[T any] func() (items []T, more bool, err error)
+
+// VirtualList is a cursor over a long list that renders only the rows in
+// view. Items are loaded a page at a time as the cursor nears the end of
+// those already loaded, so opening a list of any length costs one page.
+type VirtualList[T any] struct {
+	// Render draws one item. hovered is true for the item under the cursor.
+	Render func(item T, hovered bool) string
+	// Skip reports items the cursor passes over, such as group headings.
+	Skip func(item T) bool
+	// Keys binds the navigation actions.
+	Keys keymap.KeyMap
+
+	items  []T
+	load   PageLoader[T]
+	more   bool
+	cursor int
+	offset int
+	height int
+}
+
+func NewVirtualList[T any](render func(item T, hovered bool) string) *VirtualList[T] {
+	return &VirtualList[T]{
+		Render: render,
+		Skip:   func(T) bool { return false },
+		height: defaultListHeight,
+	}
+}
+
+// Reset replaces the items with those from load, reading its first page, and
+// puts the cursor on the first item.
+func (l *VirtualList[T]) Reset(load PageLoader[T]) error {
+	l.items, l.load, l.more = nil, load, true
+	l.cursor, l.offset = 0, 0
+	return l.MoveTo(0)
+}
+
+// SetHeight sets how many rows are shown.
+func (l *VirtualList[T]) SetHeight(height int) {
+	l.height = max(height, 1)
+	l.scroll()
+}
+
+// Len is the number of items loaded so far.
+func (l *VirtualList[T]) Len() int { return len(l.items) }
+
+// Index is the cursor's position.
+func (l *VirtualList[T]) Index() int { return l.cursor }
+
+// Current returns the item under the cursor, if there is one.
+func (l *VirtualList[T]) Current() (T, bool) {
+	var zero T
+	if l.cursor >= len(l.items) || l.Skip(l.items[l.cursor]) {
+		return zero, false
+	}
+	return l.items[l.cursor], true
+}
+
+// Update moves the cursor for the navigation actions of Keys. A page that fails to load is
+// returned as an error message.
+func (l *VirtualList[T]) Update(msg tea.Msg) tea.Cmd {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	var err error
+	switch {
+	case l.Keys.Matches(k, keymap.Up):
+		err = l.move(-1)
+	case l.Keys.Matches(k, keymap.Down):
+		err = l.move(1)
+	case l.Keys.Matches(k, keymap.PageUp):
+		err = l.move(-l.height)
+	case l.Keys.Matches(k, keymap.PageDown):
+		err = l.move(l.height)
+	case l.Keys.Matches(k, keymap.Top):
+		err = l.MoveTo(0)
+	case l.Keys.Matches(k, keymap.Bottom):
+		err = l.MoveTo(math.MaxInt)
+	}
+	if err != nil {
+		return func() tea.Msg { return err }
+	}
+	return nil
+}
+
+// MoveTo puts the cursor on item i, or the nearest item the cursor can rest
+// on, loading pages as needed. Moving past the end loads every page.
+func (l *VirtualList[T]) MoveTo(i int) error {
+	return l.moveTo(max(i, 0), 1)
+}
+
+// Focus puts the cursor on the first item match accepts among the first
+// limit, loading pages until one is found. It reports false, leaving the
+// cursor alone, when none is.
+func (l *VirtualList[T]) Focus(match func(T) bool, limit int) (bool, error) {
+	for i := 0; i < limit; i++ {
+		if err := l.ensure(i); err != nil {
+			return false, err
+		}
+		if i >= len(l.items) {
+			return false, nil
+		}
+		if !l.Skip(l.items[i]) && match(l.items[i]) {
+			return true, l.moveTo(i, 1)
+		}
+	}
+	return false, nil
+}
+
+func (l *VirtualList[T]) View() string {
+	end := min(l.offset+l.height, len(l.items))
+	rows := make([]string, 0, end-l.offset)
+	for i := l.offset; i < end; i++ {
+		rows = append(rows, l.Render(l.items[i], i == l.cursor))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (l *VirtualList[T]) move(delta int) error {
+	direction := 1
+	if delta < 0 {
+		direction = -1
+	}
+	return l.moveTo(max(l.cursor+delta, 0), direction)
+}
+
+// moveTo puts the cursor at i, stepping in direction past items it cannot
+// rest on, or back the other way when there are none.
+func (l *VirtualList[T]) moveTo(i int, direction int) error {
+	// Keep a page of items beyond the view loaded.
+	if err := l.ensure(min(i, math.MaxInt-2*l.height) + 2*l.height); err != nil {
+		return err
+	}
+	if len(l.items) == 0 {
+		l.cursor, l.offset = 0, 0
+		return nil
+	}
+	i = min(i, len(l.items)-1)
+	target, ok := l.restable(i, direction)
+	if !ok {
+		target, ok = l.restable(i, -direction)
+	}
+	if ok {
+		l.cursor = target
+	}
+	l.scroll()
+	return nil
+}
+
+func (l *VirtualList[T]) restable(i int, direction int) (int, bool) {
+	for ; i >= 0 && i < len(l.items); i += direction {
+		if !l.Skip(l.items[i]) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// scroll keeps the cursor in view, along with any headings directly above
+// it.
+func (l *VirtualList[T]) scroll() {
+	top := l.cursor
+	for top > 0 && l.Skip(l.items[top-1]) {
+		top--
+	}
+	if top < l.offset {
+		l.offset = top
+	}
+	if l.cursor >= l.offset+l.height {
+		l.offset = l.cursor - l.height + 1
+	}
+}
+
+// ensure loads pages until item i is loaded or there are no more.
+func (l *VirtualList[T]) ensure(i int) error {
+	for i >= len(l.items) && l.more {
+		items, more, err := l.load()
+		if err != nil {
+			return err
+		}
+		l.items = append(l.items, items...)
+		l.more = more && len(items) > 0
+	}
+	return nil
+}
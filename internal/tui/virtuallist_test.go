@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numbers loads 0..total-1 in pages of size, counting the pages read.
+func numbers(total int, size int, pages *int) PageLoader[int] {
+	next := 0
+	return func() ([]int, bool, error) {
+		*pages++
+		var items []int
+		for ; next < total && len(items) < size; next++ {
+			items = append(items, next)
+		}
+		return items, next < total, nil
+	}
+}
+
+func renderNumber(n int, hovered bool) string {
+	if hovered {
+		return "> " + strconv.Itoa(n)
+	}
+	return "  " + strconv.Itoa(n)
+}
+
+func press(l *VirtualList[int], keys ...tea.KeyType) {
+	for _, k := range keys {
+		_ = l.Update(tea.KeyMsg{Type: k})
+	}
+}
+
+func TestVirtualList_LoadsPagesAsTheCursorMoves(t *testing.T) {
+	pages := 0
+	l := NewVirtualList(renderNumber)
+	l.SetHeight(3)
+	require.NoError(t, l.Reset(numbers(1000, 10, &pages)))
+
+	assert.Equal(t, 1, pages, "one page covers the view and the look-ahead")
+	assert.Equal(t, "> 0\n  1\n  2", l.View())
+
+	press(l, tea.KeyDown, tea.KeyDown, tea.KeyDown)
+	assert.Equal(t, "  1\n  2\n> 3", l.View(), "only the rows in view are rendered")
+	assert.Equal(t, 1, pages)
+
+	for range 6 {
+		press(l, tea.KeyDown)
+	}
+	assert.Equal(t, 9, l.Index())
+	assert.Equal(t, 2, pages, "the next page is read before the cursor reaches it")
+
+	press(l, tea.KeyPgUp, tea.KeyHome)
+	assert.Equal(t, 0, l.Index())
+
+	press(l, tea.KeyEnd)
+	assert.Equal(t, 999, l.Index())
+	assert.Equal(t, 1000, l.Len())
+	assert.Equal(t, "  997\n  998\n> 999", l.View())
+
+	press(l, tea.KeyDown)
+	assert.Equal(t, 999, l.Index(), "the cursor stops at the end")
+}
+
+func TestVirtualList_SkipsHeadings(t *testing.T) {
+	items := []int{-1, 1, 2, -2, 3}
+	l := NewVirtualList(renderNumber)
+	l.Skip = func(n int) bool { return n < 0 }
+	l.SetHeight(2)
+	require.NoError(t, l.Reset(func() ([]int, bool, error) { return items, false, nil }))
+
+	current, ok := l.Current()
+	assert.True(t, ok)
+	assert.Equal(t, 1, current, "the cursor starts on the first item, not the heading")
+
+	press(l, tea.KeyDown, tea.KeyDown)
+	current, _ = l.Current()
+	assert.Equal(t, 3, current)
+
+	press(l, tea.KeyUp)
+	assert.Equal(t, "> 2\n  -2", l.View())
+	press(l, tea.KeyUp, tea.KeyUp)
+	current, _ = l.Current()
+	assert.Equal(t, 1, current)
+	assert.Equal(t, "  -1\n> 1", l.View(), "moving up to a group's first item shows its heading")
+}
+
+func TestVirtualList_Focus(t *testing.T) {
+	pages := 0
+	l := NewVirtualList(renderNumber)
+	require.NoError(t, l.Reset(numbers(100, 10, &pages)))
+
+	found, err := l.Focus(func(n int) bool { return n == 57 }, math.MaxInt)
+	require.NoError(t, err)
+	assert.True(t, found)
+	current, _ := l.Current()
+	assert.Equal(t, 57, current)
+
+	found, err = l.Focus(func(n int) bool { return n == 500 }, math.MaxInt)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, 57, l.Index())
+
+	pages = 0
+	require.NoError(t, l.Reset(numbers(100, 10, &pages)))
+	found, err = l.Focus(func(n int) bool { return n == 57 }, 30)
+	require.NoError(t, err)
+	assert.False(t, found, "items past the limit are not looked at")
+	assert.Equal(t, 3, pages, "pages past the limit are not read")
+}
+
+func TestVirtualList_EmptyAndFailingLoaders(t *testing.T) {
+	l := NewVirtualList(renderNumber)
+	require.NoError(t, l.Reset(func() ([]int, bool, error) { return nil, false, nil }))
+	_, ok := l.Current()
+	assert.False(t, ok)
+	assert.Empty(t, l.View())
+	press(l, tea.KeyDown, tea.KeyEnd)
+
+	boom := errors.New("boom")
+	assert.ErrorIs(t, l.Reset(func() ([]int, bool, error) { return nil, false, boom }), boom)
+
+	calls := 0
+	require.NoError(t, l.Reset(func() ([]int, bool, error) {
+		calls++
+		if calls > 1 {
+			return nil, false, boom
+		}
+		return make([]int, 30), true, nil
+	}))
+	cmd := l.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	require.NotNil(t, cmd)
+	assert.Equal(t, boom, cmd())
+}